@@ -1,8 +1,8 @@
 package bitindex
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"strings"
 )
@@ -11,27 +11,38 @@ import (
 //
 // For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Address
 func (c *Client) AddressInfo(address string) (addressInfo *AddressInfo, err error) {
+	return c.AddressInfoWithContext(context.Background(), address)
+}
+
+// AddressInfoWithContext is the same as AddressInfo() but will be canceled when the given context is done
+//
+// For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Address
+func (c *Client) AddressInfoWithContext(ctx context.Context, address string) (addressInfo *AddressInfo, err error) {
 
 	// Create the request
-	var resp string
+	var resp *requestResponse
 	// /api/v3/network/addr/address
-	resp, err = c.Request("addr/"+address, http.MethodGet, nil)
+	resp, err = c.request(ctx, &apiRequest{
+		Endpoint:   "addr/" + address,
+		Idempotent: true,
+		Method:     http.MethodGet,
+		Name:       "AddressInfo",
+	})
 	if err != nil {
 		return
 	}
 
-	// Process the response
-	addressInfo = new(AddressInfo)
-	if err = json.Unmarshal([]byte(resp), addressInfo); err != nil {
+	// Error from request?
+	if resp.StatusCode != http.StatusOK {
+		err = newAPIError(resp)
 		return
 	}
 
-	// Error from request?
-	if c.LastRequest.StatusCode != http.StatusOK {
-		err = fmt.Errorf("error: %s", addressInfo.ErrorMessage)
+	// Process the response
+	addressInfo = new(AddressInfo)
+	if err = json.Unmarshal(resp.Body, addressInfo); err != nil {
 		return
 	}
-
 	return
 }
 
@@ -39,28 +50,36 @@ func (c *Client) AddressInfo(address string) (addressInfo *AddressInfo, err erro
 //
 // For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Address
 func (c *Client) AddressUnspentTransactions(address string) (transactions UnspentTransactions, err error) {
+	return c.AddressUnspentTransactionsWithContext(context.Background(), address)
+}
+
+// AddressUnspentTransactionsWithContext is the same as AddressUnspentTransactions() but will be canceled when the given context is done
+//
+// For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Address
+func (c *Client) AddressUnspentTransactionsWithContext(ctx context.Context, address string) (transactions UnspentTransactions, err error) {
 
 	// Create the request
-	var resp string
+	var resp *requestResponse
 	// /api/v3/network/addr/address/utxo
-	resp, err = c.Request("addr/"+address+"/utxo", http.MethodGet, nil)
+	resp, err = c.request(ctx, &apiRequest{
+		Endpoint:   "addr/" + address + "/utxo",
+		Idempotent: true,
+		Method:     http.MethodGet,
+		Name:       "AddressUnspentTransactions",
+	})
 	if err != nil {
 		return
 	}
 
 	// Error from request?
-	if c.LastRequest.StatusCode != http.StatusOK {
-		var apiError APIInternalError
-		if err = json.Unmarshal([]byte(resp), &apiError); err != nil {
-			return
-		}
-		err = fmt.Errorf("error: %s", apiError.ErrorMessage)
+	if resp.StatusCode != http.StatusOK {
+		err = newAPIError(resp)
 		return
 	}
 
 	// Process the response
 	transactions = *new(UnspentTransactions)
-	if err = json.Unmarshal([]byte(resp), &transactions); err != nil {
+	if err = json.Unmarshal(resp.Body, &transactions); err != nil {
 		return
 	}
 	return
@@ -70,6 +89,13 @@ func (c *Client) AddressUnspentTransactions(address string) (transactions Unspen
 //
 // For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Address
 func (c *Client) GetTransactions(transactionRequest *GetTransactionsRequest) (response *GetTransactionsResponse, err error) {
+	return c.GetTransactionsWithContext(context.Background(), transactionRequest)
+}
+
+// GetTransactionsWithContext is the same as GetTransactions() but will be canceled when the given context is done
+//
+// For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Address
+func (c *Client) GetTransactionsWithContext(ctx context.Context, transactionRequest *GetTransactionsRequest) (response *GetTransactionsResponse, err error) {
 
 	// Got multiple addresses?
 	if len(transactionRequest.Addresses) > 0 {
@@ -84,25 +110,30 @@ func (c *Client) GetTransactions(transactionRequest *GetTransactionsRequest) (re
 	}
 
 	// Create the request
-	var resp string
+	var resp *requestResponse
 	// /api/v3/network/addrs/txs
-	resp, err = c.Request("addrs/txs", http.MethodPost, data)
+	resp, err = c.request(ctx, &apiRequest{
+		Endpoint:   "addrs/txs",
+		Idempotent: true, // read-only query (safe to retry)
+		Method:     http.MethodPost,
+		Name:       "GetTransactions",
+		Payload:    data,
+	})
 	if err != nil {
 		return
 	}
 
-	// Process the response
-	response = new(GetTransactionsResponse)
-	if err = json.Unmarshal([]byte(resp), &response); err != nil {
+	// Error from request?
+	if resp.StatusCode != http.StatusOK {
+		err = newAPIError(resp)
 		return
 	}
 
-	// Error from request?
-	if c.LastRequest.StatusCode != http.StatusOK {
-		err = fmt.Errorf("error: %s", response.ErrorMessage)
+	// Process the response
+	response = new(GetTransactionsResponse)
+	if err = json.Unmarshal(resp.Body, &response); err != nil {
 		return
 	}
-
 	return
 }
 
@@ -110,6 +141,13 @@ func (c *Client) GetTransactions(transactionRequest *GetTransactionsRequest) (re
 //
 // For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Address
 func (c *Client) GetUnspentTransactions(transactionRequest *GetUnspentTransactionsRequest) (transactions UnspentTransactions, err error) {
+	return c.GetUnspentTransactionsWithContext(context.Background(), transactionRequest)
+}
+
+// GetUnspentTransactionsWithContext is the same as GetUnspentTransactions() but will be canceled when the given context is done
+//
+// For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Address
+func (c *Client) GetUnspentTransactionsWithContext(ctx context.Context, transactionRequest *GetUnspentTransactionsRequest) (transactions UnspentTransactions, err error) {
 
 	// Got multiple addresses? (Handle this for the user)
 	if len(transactionRequest.Addresses) > 0 {
@@ -123,34 +161,39 @@ func (c *Client) GetUnspentTransactions(transactionRequest *GetUnspentTransactio
 		return
 	}
 
-	// Do we have a sort
-	endpoint := "addrs/utxo"
-	if len(transactionRequest.Sort) > 0 {
-		endpoint = fmt.Sprintf("%s?sort=%s", endpoint, transactionRequest.Sort)
+	// Do we have a sort (validated & escaped)
+	var endpoint string
+	if endpoint, err = transactionRequest.Sort.endpoint("addrs/utxo"); err != nil {
+		return
 	}
 
 	// Create the request
-	var resp string
+	var resp *requestResponse
 	// /api/v3/network/addrs/utxo
-	resp, err = c.Request(endpoint, http.MethodPost, data)
+	resp, err = c.request(ctx, &apiRequest{
+		Endpoint:   endpoint,
+		Idempotent: true, // read-only query (safe to retry)
+		Method:     http.MethodPost,
+		Name:       "GetUnspentTransactions",
+		Payload:    data,
+	})
 	if err != nil {
 		return
 	}
 
 	// Error from request?
-	if c.LastRequest.StatusCode != http.StatusOK {
-		var apiError APIInternalError
-		if err = json.Unmarshal([]byte(resp), &apiError); err != nil {
-			return
-		}
-		err = fmt.Errorf("error: %s", apiError.ErrorMessage)
+	if resp.StatusCode != http.StatusOK {
+		err = newAPIError(resp)
 		return
 	}
 
 	// Process the response
 	transactions = *new(UnspentTransactions)
-	if err = json.Unmarshal([]byte(resp), &transactions); err != nil {
+	if err = json.Unmarshal(resp.Body, &transactions); err != nil {
 		return
 	}
+
+	// Sort locally (in case the server ignored the sort)
+	err = transactions.Sort(transactionRequest.Sort)
 	return
 }
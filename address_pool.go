@@ -0,0 +1,258 @@
+package bitindex
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultAddressClaimTime is how long a handed out address stays claimed in the store
+	DefaultAddressClaimTime = 7 * 24 * time.Hour
+
+	// DefaultAddressPoolSize is the number of addresses the pool keeps ready
+	DefaultAddressPoolSize = 5
+
+	// DefaultAddressReserveTime is the reservation time requested from GetXpubNextAddress
+	DefaultAddressReserveTime = time.Hour
+)
+
+// ErrAddressPoolExhausted is returned when the pool could not get an unclaimed address
+var ErrAddressPoolExhausted = errors.New("no unclaimed address available")
+
+// AddressStore is a shared store that coordinates the handed out addresses across instances
+//
+// Implement it with any shared storage that has an atomic "set if not exists" (IE: Redis SET NX
+// with an expiry, a database unique key), the default is an in-memory store for each pool (pass the
+// same store to every pool that must coordinate, a shared MemoryAddressStore works in one process)
+type AddressStore interface {
+	// Claim marks the address as handed out until the expiry, returns false if it is already claimed
+	Claim(ctx context.Context, xPub, address string, expires time.Time) (claimed bool, err error)
+}
+
+// memoryStorePruneInterval is how often the expired claims are removed from a MemoryAddressStore
+const memoryStorePruneInterval = time.Minute
+
+// MemoryAddressStore is an in-memory AddressStore (expired claims are removed as new claims are made)
+type MemoryAddressStore struct {
+	claims map[string]time.Time // are the claims (xpub/address) and their expiry
+	mu     sync.Mutex           // is the lock for the claims
+	now    func() time.Time     // is the clock (replaced in tests)
+	pruned time.Time            // is when the expired claims were last removed
+}
+
+// NewMemoryAddressStore creates an in-memory store
+func NewMemoryAddressStore() *MemoryAddressStore {
+	return &MemoryAddressStore{claims: make(map[string]time.Time), now: time.Now}
+}
+
+// Claim marks the address as handed out until the expiry, returns false if it is already claimed
+func (s *MemoryAddressStore) Claim(_ context.Context, xPub, address string, expires time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.pruned) >= memoryStorePruneInterval {
+		for key, expiry := range s.claims {
+			if !now.Before(expiry) {
+				delete(s.claims, key)
+			}
+		}
+		s.pruned = now
+	}
+
+	key := xPub + "/" + address
+	if expiry, ok := s.claims[key]; ok && now.Before(expiry) {
+		return false, nil
+	}
+	s.claims[key] = expires
+	return true, nil
+}
+
+// AddressPoolOptions are the options for the AddressPool
+type AddressPoolOptions struct {
+	ClaimTime     time.Duration // is how long a handed out address stays claimed in the store (0 uses DefaultAddressClaimTime)
+	RefreshBefore time.Duration // is how long before the reservation expires an address is dropped (0 uses 10% of the reserve time)
+	ReserveTime   time.Duration // is the reservation time for GetXpubNextAddress (0 uses DefaultAddressReserveTime)
+	Size          int           // is the number of addresses to keep ready (0 uses DefaultAddressPoolSize)
+	Store         AddressStore  // is the shared store (nil uses a new MemoryAddressStore for this pool only)
+}
+
+// AddressPool hands out unique receive addresses of an xpub (reserved with GetXpubNextAddress)
+//
+// Addresses are pre-fetched and dropped before their reservation expires (Fill() or Run()). Every
+// address is claimed in the Store before it is handed out, so it is never handed to two callers of the
+// pools sharing the Store (pass the same Store to every pool or instance, each pool has its own by default)
+type AddressPool struct {
+	cache     []pooledAddress      // are the reserved addresses ready to hand out
+	handedOut map[string]time.Time // are the addresses handed out (or claimed elsewhere) until their reservation ends
+	mu        sync.Mutex           // is the lock for the cache & handed out addresses
+	now       func() time.Time     // is the clock (replaced in tests)
+	options   AddressPoolOptions   // are the options (with the defaults)
+	service   XpubService          // is the client (or any XpubService)
+	xPub      string               // is the xpub
+}
+
+// pooledAddress is a reserved address and the end of the reservation
+type pooledAddress struct {
+	address XPubAddress // is the address
+	expires time.Time   // is when the reservation ends
+}
+
+// NewAddressPool creates a pool of receive addresses for the xpub (call Fill() to pre-fetch)
+func NewAddressPool(service XpubService, xPub string, options *AddressPoolOptions) *AddressPool {
+	pool := &AddressPool{handedOut: make(map[string]time.Time), now: time.Now, service: service, xPub: xPub}
+	if options != nil {
+		pool.options = *options
+	}
+	if pool.options.ClaimTime <= 0 {
+		pool.options.ClaimTime = DefaultAddressClaimTime
+	}
+	if pool.options.ReserveTime < time.Second {
+		pool.options.ReserveTime = DefaultAddressReserveTime
+	}
+	if pool.options.RefreshBefore <= 0 || pool.options.RefreshBefore >= pool.options.ReserveTime {
+		pool.options.RefreshBefore = pool.options.ReserveTime / 10
+	}
+	if pool.options.Size <= 0 {
+		pool.options.Size = DefaultAddressPoolSize
+	}
+	if pool.options.Store == nil {
+		pool.options.Store = NewMemoryAddressStore()
+	}
+	return pool
+}
+
+// Next will hand out a unique receive address (fetching one if the pool is empty)
+func (p *AddressPool) Next(ctx context.Context) (*XPubAddress, error) {
+	for attempt := 0; attempt < 2*p.options.Size+10; attempt++ {
+		address, ok := p.pop()
+		if !ok {
+			if err := p.fetch(ctx); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		// Claimed by another instance?
+		claimed, err := p.options.Store.Claim(ctx, p.xPub, address.Address, p.now().Add(p.options.ClaimTime))
+		if err != nil {
+			return nil, err
+		} else if claimed {
+			return &address, nil
+		}
+	}
+	return nil, ErrAddressPoolExhausted
+}
+
+// Fill will drop the addresses that are about to expire and fetch addresses up to the pool size
+func (p *AddressPool) Fill(ctx context.Context) error {
+	for {
+		p.mu.Lock()
+		p.dropExpiring()
+		missing := p.options.Size - len(p.cache)
+		p.mu.Unlock()
+
+		if missing <= 0 {
+			return nil
+		}
+		before := p.Len()
+		if err := p.fetch(ctx); err != nil {
+			return err
+		} else if p.Len() <= before {
+			return ErrAddressPoolExhausted
+		}
+	}
+}
+
+// Run will Fill() the pool at the interval until the context is done (run it in a goroutine)
+//
+// Errors are passed to onError (if set) and the next interval tries again
+func (p *AddressPool) Run(ctx context.Context, interval time.Duration, onError func(err error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := p.Fill(ctx); err != nil && ctx.Err() == nil && onError != nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Len returns the number of addresses ready to hand out
+func (p *AddressPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.cache)
+}
+
+// pop removes the next address that is not about to expire (marked as handed out)
+func (p *AddressPool) pop() (address XPubAddress, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.dropExpiring()
+	if len(p.cache) == 0 {
+		return
+	}
+	address, ok = p.cache[0].address, true
+	p.handedOut[address.Address] = p.cache[0].expires
+	p.cache = p.cache[1:]
+	return
+}
+
+// dropExpiring removes the addresses whose reservation is about to expire (lock must be held)
+//
+// The handed out addresses are forgotten when their reservation ends, the Store claim prevents
+// handing them out again if the api returns them later
+func (p *AddressPool) dropExpiring() {
+	now := p.now()
+	for address, expires := range p.handedOut {
+		if !now.Before(expires) {
+			delete(p.handedOut, address)
+		}
+	}
+
+	cutoff := now.Add(p.options.RefreshBefore)
+	cache := p.cache[:0]
+	for _, pooled := range p.cache {
+		if pooled.expires.After(cutoff) {
+			cache = append(cache, pooled)
+		}
+	}
+	p.cache = cache
+}
+
+// fetch will reserve the next address(es) and add the new ones to the cache (the lock is not held)
+func (p *AddressPool) fetch(ctx context.Context) error {
+	requested := p.now()
+	addresses, err := p.service.GetXpubNextAddressWithContext(ctx, p.xPub, int(p.options.ReserveTime/time.Second))
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, address := range addresses {
+		if _, ok := p.handedOut[address.Address]; ok || len(address.Address) == 0 || p.cached(address.Address) {
+			continue
+		}
+		p.cache = append(p.cache, pooledAddress{address: address, expires: requested.Add(p.options.ReserveTime)})
+	}
+	return nil
+}
+
+// cached returns true if the address is in the cache (lock must be held)
+func (p *AddressPool) cached(address string) bool {
+	for _, pooled := range p.cache {
+		if pooled.address.Address == address {
+			return true
+		}
+	}
+	return false
+}
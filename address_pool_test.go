@@ -0,0 +1,223 @@
+package bitindex_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mrz1836/go-bitindex"
+	"github.com/mrz1836/go-bitindex/bitindextest"
+)
+
+// xpubAddresses adds the xpub with count receive addresses (1address0...)
+func xpubAddresses(xPub string, count int) serverSeed {
+	return func(_ *testing.T, server *bitindextest.Server) {
+		for num := 0; num < count; num++ {
+			server.AddXpub(xPub, bitindex.XPubAddress{Address: fmt.Sprintf("1address%d", num), Num: num, Path: fmt.Sprintf("0/%d", num)})
+		}
+	}
+}
+
+// nextAddressRequests returns the number of addrs/next requests
+func nextAddressRequests(server *bitindextest.Server, xPub string) (count int) {
+	for _, request := range server.Requests() {
+		if request.Path == "xpub/"+xPub+"/addrs/next" {
+			count++
+		}
+	}
+	return
+}
+
+// TestAddressPool_Next tests handing out unique addresses under load
+func TestAddressPool_Next(t *testing.T) {
+	t.Parallel()
+
+	xPub := "xpub-pool-next"
+	server, client := newFakeServer(t, nil, xpubAddresses(xPub, 100))
+	pool := bitindex.NewAddressPool(client, xPub, &bitindex.AddressPoolOptions{Size: 3})
+
+	if err := pool.Fill(context.Background()); err != nil {
+		t.Fatal(err)
+	} else if pool.Len() != 3 || nextAddressRequests(server, xPub) != 3 {
+		t.Fatalf("expected 3 pre-fetched addresses got: %d (%d requests)", pool.Len(), nextAddressRequests(server, xPub))
+	}
+	if request := server.Requests()[0]; request.RawQuery != "reserveTime=3600" {
+		t.Fatalf("expected the default reserve time got: %s", request.RawQuery)
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	seen := make(map[string]int)
+	for i := 0; i < 40; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			address, err := pool.Next(context.Background())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			seen[address.Address]++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if len(seen) != 40 {
+		t.Fatalf("expected 40 unique addresses got: %d", len(seen))
+	}
+	for address, count := range seen {
+		if count != 1 {
+			t.Fatalf("expected %s once got: %d", address, count)
+		}
+	}
+}
+
+// TestAddressPool_Store tests pools sharing a store when the api returns the same addresses
+func TestAddressPool_Store(t *testing.T) {
+	t.Parallel()
+
+	t.Run("shared store", func(t *testing.T) {
+
+		// Two api instances that both hand out 1address0 first (IE: the reservation lapsed)
+		xPub := "xpub-pool-store"
+		_, firstClient := newFakeServer(t, nil, xpubAddresses(xPub, 2))
+		_, secondClient := newFakeServer(t, nil, xpubAddresses(xPub, 2))
+
+		store := bitindex.NewMemoryAddressStore()
+		first := bitindex.NewAddressPool(firstClient, xPub, &bitindex.AddressPoolOptions{Store: store})
+		second := bitindex.NewAddressPool(secondClient, xPub, &bitindex.AddressPoolOptions{Store: store})
+
+		address, err := first.Next(context.Background())
+		if err != nil || address.Address != "1address0" {
+			t.Fatalf("expected 1address0 got: %v %v", address, err)
+		}
+		if address, err = second.Next(context.Background()); err != nil || address.Address != "1address1" {
+			t.Fatalf("expected 1address1 got: %v %v", address, err)
+		}
+
+		// Every address is claimed (or reserved)
+		if _, err = first.Next(context.Background()); !errors.Is(err, bitindex.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound got: %v", err)
+		}
+
+		// Claims expire
+		bitindex.SetAddressStoreClock(store, func() time.Time { return time.Now().Add(bitindex.DefaultAddressClaimTime + time.Minute) })
+		if claimed, err := store.Claim(context.Background(), xPub, "1address0", time.Now()); err != nil || !claimed {
+			t.Fatalf("expected the expired claim to be claimed again: %v", err)
+		}
+	})
+
+	t.Run("default store", func(t *testing.T) {
+
+		// Pools without a store do not coordinate (each has its own store)
+		xPub := "xpub-pool-default-store"
+		_, firstClient := newFakeServer(t, nil, xpubAddresses(xPub, 2))
+		_, secondClient := newFakeServer(t, nil, xpubAddresses(xPub, 2))
+		first := bitindex.NewAddressPool(firstClient, xPub, nil)
+		second := bitindex.NewAddressPool(secondClient, xPub, nil)
+
+		address, err := first.Next(context.Background())
+		if err != nil || address.Address != "1address0" {
+			t.Fatalf("expected 1address0 got: %v %v", address, err)
+		}
+		if address, err = second.Next(context.Background()); err != nil || address.Address != "1address0" {
+			t.Fatalf("expected 1address0 got: %v %v", address, err)
+		}
+	})
+}
+
+// TestAddressPool_Refresh tests dropping the addresses before the reservation expires
+func TestAddressPool_Refresh(t *testing.T) {
+	t.Parallel()
+
+	xPub := "xpub-pool-refresh"
+	server, client := newFakeServer(t, nil, xpubAddresses(xPub, 10))
+	pool := bitindex.NewAddressPool(client, xPub, &bitindex.AddressPoolOptions{RefreshBefore: time.Minute, ReserveTime: 10 * time.Minute, Size: 2})
+
+	now := time.Now()
+	bitindex.SetAddressPoolClock(pool, func() time.Time { return now })
+	if err := pool.Fill(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Still reserved for more than a minute
+	now = now.Add(8 * time.Minute)
+	if err := pool.Fill(context.Background()); err != nil {
+		t.Fatal(err)
+	} else if requests := nextAddressRequests(server, xPub); requests != 2 {
+		t.Fatalf("expected 2 requests got: %d", requests)
+	}
+
+	// Less than a minute left, both are replaced
+	now = now.Add(90 * time.Second)
+	if err := pool.Fill(context.Background()); err != nil {
+		t.Fatal(err)
+	} else if requests := nextAddressRequests(server, xPub); requests != 4 || pool.Len() != 2 {
+		t.Fatalf("expected 4 requests and 2 addresses got: %d %d", requests, pool.Len())
+	}
+	if address, err := pool.Next(context.Background()); err != nil || address.Address != "1address2" {
+		t.Fatalf("expected 1address2 got: %v %v", address, err)
+	}
+
+	// The handed out address is forgotten when the reservation ends
+	if handedOut := bitindex.AddressPoolHandedOut(pool); handedOut != 1 {
+		t.Fatalf("expected 1 handed out address got: %d", handedOut)
+	}
+	now = now.Add(11 * time.Minute)
+	if err := pool.Fill(context.Background()); err != nil {
+		t.Fatal(err)
+	} else if handedOut := bitindex.AddressPoolHandedOut(pool); handedOut != 0 {
+		t.Fatalf("expected no handed out addresses got: %d", handedOut)
+	}
+
+	// Run fills until canceled
+	if _, err := pool.Next(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		pool.Run(ctx, time.Hour, nil)
+		close(done)
+	}()
+	for pool.Len() != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+}
+
+// TestAddressPool_Errors tests the failed requests
+func TestAddressPool_Errors(t *testing.T) {
+	t.Parallel()
+
+	xPub := "xpub-pool-errors"
+	server, client := newFakeServer(t, noRetryOptions(), xpubAddresses(xPub, 10))
+	server.FailMatching(http.StatusServiceUnavailable, func(req bitindextest.Request) bool { return true })
+	pool := bitindex.NewAddressPool(client, xPub, nil)
+
+	if _, err := pool.Next(context.Background()); !errors.Is(err, bitindex.ErrServerError) {
+		t.Fatalf("expected ErrServerError got: %v", err)
+	}
+	if err := pool.Fill(context.Background()); !errors.Is(err, bitindex.ErrServerError) {
+		t.Fatalf("expected ErrServerError got: %v", err)
+	}
+
+	var errs []error
+	ctx, cancel := context.WithCancel(context.Background())
+	pool.Run(ctx, time.Millisecond, func(err error) {
+		errs = append(errs, err)
+		if len(errs) == 2 {
+			cancel()
+		}
+	})
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors got: %d", len(errs))
+	}
+}
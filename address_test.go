@@ -4,13 +4,8 @@ import "testing"
 
 // TestClient_AddressInfo tests the AddressInfo()
 func TestClient_AddressInfo(t *testing.T) {
-	// Skip this test in short mode (not needed)
-	if testing.Short() {
-		t.Skip("skipping testing in short mode")
-	}
-
-	// Create a new client object to handle your queries (supply an API Key)
-	client, err := NewClient(testAPIKey, NetworkMain, nil)
+	// Create a new client object to handle your queries (replays the cassette if recorded)
+	client, err := newCassetteClient(t, NetworkMain)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -35,13 +30,8 @@ func TestClient_AddressInfo(t *testing.T) {
 
 // TestClient_AddressUnspentTransactions tests the AddressUnspentTransactions()
 func TestClient_AddressUnspentTransactions(t *testing.T) {
-	// Skip this test in short mode (not needed)
-	if testing.Short() {
-		t.Skip("skipping testing in short mode")
-	}
-
-	// Create a new client object to handle your queries (supply an API Key)
-	client, err := NewClient(testAPIKey, NetworkMain, nil)
+	// Create a new client object to handle your queries (replays the cassette if recorded)
+	client, err := newCassetteClient(t, NetworkMain)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -62,13 +52,8 @@ func TestClient_AddressUnspentTransactions(t *testing.T) {
 
 // TestClient_GetTransactions tests the GetTransactions()
 func TestClient_GetTransactions(t *testing.T) {
-	// Skip this test in short mode (not needed)
-	if testing.Short() {
-		t.Skip("skipping testing in short mode")
-	}
-
-	// Create a new client object to handle your queries (supply an API Key)
-	client, err := NewClient(testAPIKey, NetworkMain, nil)
+	// Create a new client object to handle your queries (replays the cassette if recorded)
+	client, err := newCassetteClient(t, NetworkMain)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -99,13 +84,8 @@ func TestClient_GetTransactions(t *testing.T) {
 
 // TestClient_GetTransactionsMultiple tests the GetTransactions()
 func TestClient_GetTransactionsMultiple(t *testing.T) {
-	// Skip this test in short mode (not needed)
-	if testing.Short() {
-		t.Skip("skipping testing in short mode")
-	}
-
-	// Create a new client object to handle your queries (supply an API Key)
-	client, err := NewClient(testAPIKey, NetworkMain, nil)
+	// Create a new client object to handle your queries (replays the cassette if recorded)
+	client, err := newCassetteClient(t, NetworkMain)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -136,13 +116,8 @@ func TestClient_GetTransactionsMultiple(t *testing.T) {
 
 // TestClient_GetUnspentTransactions tests the GetUnspentTransactions()
 func TestClient_GetUnspentTransactions(t *testing.T) {
-	// Skip this test in short mode (not needed)
-	if testing.Short() {
-		t.Skip("skipping testing in short mode")
-	}
-
-	// Create a new client object to handle your queries (supply an API Key)
-	client, err := NewClient(testAPIKey, NetworkMain, nil)
+	// Create a new client object to handle your queries (replays the cassette if recorded)
+	client, err := newCassetteClient(t, NetworkMain)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -0,0 +1,92 @@
+package bitindex
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// base58Alphabet is the bitcoin base58 alphabet
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// Address versions (the first byte of a base58check address)
+const (
+	mainPubKeyHashVersion byte = 0x00
+	mainScriptHashVersion byte = 0x05
+	testPubKeyHashVersion byte = 0x6f
+	testScriptHashVersion byte = 0xc4
+)
+
+// base58Encode returns the base58 encoding of the data (leading zero bytes become "1")
+func base58Encode(data []byte) string {
+	number := new(big.Int).SetBytes(data)
+	radix := big.NewInt(58)
+	modulo := new(big.Int)
+
+	var encoded []byte
+	for number.Sign() > 0 {
+		number.DivMod(number, radix, modulo)
+		encoded = append(encoded, base58Alphabet[modulo.Int64()])
+	}
+	for _, b := range data {
+		if b != 0 {
+			break
+		}
+		encoded = append(encoded, base58Alphabet[0])
+	}
+	return string(reverseBytes(encoded))
+}
+
+// errInvalidBase58 is returned when a base58check string can not be decoded
+var errInvalidBase58 = errors.New("invalid base58check encoding")
+
+// base58Decode returns the data of the base58 string (leading "1" become zero bytes)
+func base58Decode(value string) ([]byte, error) {
+	number := new(big.Int)
+	radix := big.NewInt(58)
+	for _, char := range value {
+		index := strings.IndexRune(base58Alphabet, char)
+		if index < 0 {
+			return nil, errInvalidBase58
+		}
+		number.Mul(number, radix).Add(number, big.NewInt(int64(index)))
+	}
+
+	zeros := 0
+	for zeros < len(value) && value[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+	return append(make([]byte, zeros), number.Bytes()...), nil
+}
+
+// base58CheckDecode returns the data of the base58check string (without the checksum)
+func base58CheckDecode(value string) ([]byte, error) {
+	data, err := base58Decode(value)
+	if err != nil {
+		return nil, err
+	} else if len(data) < 5 || !bytes.Equal(doubleSha256(data[:len(data)-4])[:4], data[len(data)-4:]) {
+		return nil, errInvalidBase58
+	}
+	return data[:len(data)-4], nil
+}
+
+// base58CheckEncode returns the base58 encoding of the version, payload and checksum
+func base58CheckEncode(version byte, payload []byte) string {
+	data := append([]byte{version}, payload...)
+	return base58Encode(append(data, doubleSha256(data)[:4]...))
+}
+
+// hash160Address returns the address of the hash160 for the network
+func hash160Address(hash []byte, network NetworkType, scriptHash bool) string {
+	version := mainPubKeyHashVersion
+	switch {
+	case network != NetworkMain && scriptHash:
+		version = testScriptHashVersion
+	case network != NetworkMain:
+		version = testPubKeyHashVersion
+	case scriptHash:
+		version = mainScriptHashVersion
+	}
+	return base58CheckEncode(version, hash)
+}
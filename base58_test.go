@@ -0,0 +1,18 @@
+package bitindex
+
+import "testing"
+
+// TestBase58Encode tests the base58 encoding
+func TestBase58Encode(t *testing.T) {
+	t.Parallel()
+
+	if encoded := base58Encode([]byte("hello world")); encoded != "StV1DL6CwTryKyV" {
+		t.Fatalf("unexpected encoding: %s", encoded)
+	}
+	if encoded := base58Encode([]byte{0, 0, 1}); encoded != "112" {
+		t.Fatalf("unexpected encoding: %s", encoded)
+	}
+	if address := hash160Address(make([]byte, 20), NetworkTest, false); address != "mfWxJ45yp2SFn7UciZyNpvDKrzbhyfKrY8" {
+		t.Fatalf("unexpected address: %s", address)
+	}
+}
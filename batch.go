@@ -0,0 +1,208 @@
+package bitindex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultBatchSize is the number of addresses per request for the batched requests
+	DefaultBatchSize = 20
+
+	// DefaultBatchConcurrency is the number of batch requests in flight
+	DefaultBatchConcurrency = 4
+)
+
+// BatchOptions are the options for the batched requests
+type BatchOptions struct {
+	BatchSize   int // is the number of addresses per request (0 uses DefaultBatchSize)
+	Concurrency int // is the number of requests in flight (0 uses DefaultBatchConcurrency)
+}
+
+// BatchFailure is a batch that failed
+type BatchFailure struct {
+	Addresses []string // are the addresses of the batch
+	Err       error    // is the error from the request
+}
+
+// BatchError is returned when some batches failed (the results of the other batches are still returned)
+type BatchError struct {
+	Batches  int            // is the number of batches
+	Failures []BatchFailure // are the failed batches
+}
+
+// Error returns the error message
+func (e *BatchError) Error() string {
+	if len(e.Failures) == 0 {
+		return fmt.Sprintf("0 of %d batches failed", e.Batches)
+	}
+	return fmt.Sprintf("%d of %d batches failed: %s", len(e.Failures), e.Batches, e.Failures[0].Err.Error())
+}
+
+// Is returns true if any batch failed with the target error (IE: errors.Is(err, ErrRateLimited))
+func (e *BatchError) Is(target error) bool {
+	for _, failure := range e.Failures {
+		if errors.Is(failure.Err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetTransactionsBatched is the same as GetTransactions() for any number of addresses (all pages)
+//
+// The addresses are split into batches that are requested concurrently, the transactions are
+// merged (de-duplicated by txid) and sorted (unconfirmed first, then by height descending)
+func GetTransactionsBatched(ctx context.Context, service AddressService, transactionRequest *GetTransactionsRequest, options *BatchOptions) (transactions []Transaction, err error) {
+
+	// Each batch walks all of its pages
+	batches := splitBatches(batchAddresses(transactionRequest.Address, transactionRequest.Addresses), options)
+	results := make([][]Transaction, len(batches))
+	err = runBatches(ctx, batches, options, func(ctx context.Context, index int) error {
+		request := *transactionRequest
+		request.Address, request.Addresses = "", batches[index]
+
+		iterator := NewTransactionIterator(ctx, service, &request, MaxTransactionsPageSize)
+		for iterator.Next() {
+			results[index] = append(results[index], *iterator.Transaction())
+		}
+		return iterator.Err()
+	})
+
+	// Merge the batches (a transaction can involve addresses from many batches)
+	seen := make(map[string]struct{})
+	for _, batch := range results {
+		for _, tx := range batch {
+			if _, ok := seen[tx.TxID]; !ok {
+				seen[tx.TxID] = struct{}{}
+				transactions = append(transactions, tx)
+			}
+		}
+	}
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return newestFirst(transactions[i].BlockHeight, transactions[j].BlockHeight)
+	})
+	return
+}
+
+// GetUnspentTransactionsBatched is the same as GetUnspentTransactions() for any number of addresses
+//
+// The addresses are split into batches that are requested concurrently, the UTXOs are merged
+// (de-duplicated by txid:vout) and sorted by the request sort
+func GetUnspentTransactionsBatched(ctx context.Context, service AddressService, transactionRequest *GetUnspentTransactionsRequest, options *BatchOptions) (transactions UnspentTransactions, err error) {
+
+	batches := splitBatches(batchAddresses(transactionRequest.Address, transactionRequest.Addresses), options)
+	results := make([]UnspentTransactions, len(batches))
+	err = runBatches(ctx, batches, options, func(ctx context.Context, index int) (err error) {
+		request := *transactionRequest
+		request.Address, request.Addresses = "", batches[index]
+		results[index], err = service.GetUnspentTransactionsWithContext(ctx, &request)
+		return
+	})
+
+	// Merge the batches (in batch order)
+	seen := make(map[string]struct{})
+	for _, batch := range results {
+		for _, utxo := range batch {
+			key := utxo.TxID + ":" + strconv.Itoa(utxo.Vout)
+			if _, ok := seen[key]; !ok {
+				seen[key] = struct{}{}
+				transactions = append(transactions, utxo)
+			}
+		}
+	}
+
+	// Sort across the batches (an invalid sort already failed every batch)
+	_ = transactions.Sort(transactionRequest.Sort)
+	return
+}
+
+// splitBatches will split the addresses into batches of the batch size
+func splitBatches(addresses []string, options *BatchOptions) (batches [][]string) {
+	batchSize := DefaultBatchSize
+	if options != nil && options.BatchSize > 0 {
+		batchSize = options.BatchSize
+	}
+	for start := 0; start < len(addresses); start += batchSize {
+		end := start + batchSize
+		if end > len(addresses) {
+			end = len(addresses)
+		}
+		batches = append(batches, addresses[start:end:end])
+	}
+	return
+}
+
+// runBatches will run the batch function for each batch (by index) with bounded concurrency
+//
+// Returns a *BatchError with the failed batches (nil if all batches succeeded)
+func runBatches(ctx context.Context, batches [][]string, options *BatchOptions, fn func(ctx context.Context, index int) error) error {
+	concurrency := DefaultBatchConcurrency
+	if options != nil && options.Concurrency > 0 {
+		concurrency = options.Concurrency
+	}
+
+	// Fan out
+	failures := make([]*BatchFailure, len(batches))
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, batch := range batches {
+		select {
+		case <-ctx.Done():
+			failures[i] = &BatchFailure{Addresses: batch, Err: ctx.Err()}
+			continue
+		case semaphore <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(i int, batch []string) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			if err := fn(ctx, i); err != nil {
+				failures[i] = &BatchFailure{Addresses: batch, Err: err}
+			}
+		}(i, batch)
+	}
+	wg.Wait()
+
+	// Collect the failures (in batch order)
+	batchErr := &BatchError{Batches: len(batches)}
+	for _, failure := range failures {
+		if failure != nil {
+			batchErr.Failures = append(batchErr.Failures, *failure)
+		}
+	}
+	if len(batchErr.Failures) == 0 {
+		return nil
+	}
+	return batchErr
+}
+
+// batchAddresses returns the unique addresses from the comma separated address and the list
+func batchAddresses(address string, addresses []string) (unique []string) {
+	seen := make(map[string]struct{})
+	for _, value := range append(strings.Split(address, ","), addresses...) {
+		if value = strings.TrimSpace(value); len(value) > 0 {
+			if _, ok := seen[value]; !ok {
+				seen[value] = struct{}{}
+				unique = append(unique, value)
+			}
+		}
+	}
+	return
+}
+
+// newestFirst sorts unconfirmed transactions (height 0) first, then by height descending
+func newestFirst(heightA, heightB int64) bool {
+	if heightA <= 0 || heightB <= 0 {
+		return heightA <= 0 && heightB > 0
+	}
+	return heightA > heightB
+}
@@ -0,0 +1,155 @@
+package bitindex_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrz1836/go-bitindex"
+	"github.com/mrz1836/go-bitindex/bitindextest"
+)
+
+// testBatchAddresses returns count addresses (1addr-0...)
+func testBatchAddresses(count int) (addresses []string) {
+	for i := 0; i < count; i++ {
+		addresses = append(addresses, fmt.Sprintf("1addr-%d", i))
+	}
+	return
+}
+
+// batchTransactions adds one transaction for each address (tx-{address} at 200+n) and a shared
+// transaction at 100 with one (multisig) output for every tenth address, so it is in every batch of 10
+//
+// Every request takes 10ms, so the batches overlap
+func batchTransactions(addresses []string) serverSeed {
+	return func(_ *testing.T, server *bitindextest.Server) {
+		server.SetLatency(10 * time.Millisecond)
+
+		shared := bitindextest.NewTransaction("shared", 100, nil, []bitindextest.Output{{Satoshis: 1000}})
+		for i := 0; i < len(addresses); i += 10 {
+			shared.Vout[0].ScriptPubKey.Addresses = append(shared.Vout[0].ScriptPubKey.Addresses, addresses[i])
+		}
+		server.AddTransaction(shared)
+		for i, address := range addresses {
+			server.AddTransaction(bitindextest.NewTransaction("tx-"+address, int64(200+i), nil, []bitindextest.Output{{Address: address, Satoshis: 500}}))
+		}
+	}
+}
+
+// batchRequests returns the addresses of each request to the route (IE: addrs/utxo)
+func batchRequests(server *bitindextest.Server, route string) (batches [][]string) {
+	for _, request := range server.Requests() {
+		if request.Path == route {
+			var body bitindex.GetTransactionsRequest
+			_ = json.Unmarshal([]byte(request.Body), &body)
+			batches = append(batches, strings.Split(body.Address, ","))
+		}
+	}
+	return
+}
+
+// TestGetUnspentTransactionsBatched tests the batched UTXO request
+func TestGetUnspentTransactionsBatched(t *testing.T) {
+	t.Parallel()
+
+	server, client := newFakeServer(t, nil, batchTransactions(testBatchAddresses(25)))
+
+	addresses := append(testBatchAddresses(25), "1addr-0") // duplicate address
+	utxos, err := bitindex.GetUnspentTransactionsBatched(context.Background(), client, &bitindex.GetUnspentTransactionsRequest{Addresses: addresses}, &bitindex.BatchOptions{BatchSize: 10, Concurrency: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if batches := batchRequests(server, "addrs/utxo"); len(batches) != 3 || len(batches[0]) != 10 {
+		t.Fatalf("expected 3 batches of up to 10 got: %v", batches)
+	}
+	if server.MaxInFlight() > 2 {
+		t.Fatalf("expected at most 2 requests in flight got: %d", server.MaxInFlight())
+	}
+	if len(utxos) != 26 || utxos[0].TxID != "shared" || utxos[1].TxID != "tx-1addr-0" || utxos[25].TxID != "tx-1addr-24" {
+		t.Fatalf("expected 25 UTXOs and one shared UTXO (in batch order) got: %d", len(utxos))
+	}
+}
+
+// TestGetTransactionsBatched tests the batched transactions request
+func TestGetTransactionsBatched(t *testing.T) {
+	t.Parallel()
+
+	server, client := newFakeServer(t, nil, batchTransactions(testBatchAddresses(5)))
+
+	transactions, err := bitindex.GetTransactionsBatched(context.Background(), client, &bitindex.GetTransactionsRequest{Address: "1addr-0, 1addr-1", Addresses: testBatchAddresses(5)}, &bitindex.BatchOptions{BatchSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if batches := batchRequests(server, "addrs/txs"); len(batches) != 3 {
+		t.Fatalf("expected 3 batches got: %v", batches)
+	}
+	if len(transactions) != 6 || transactions[5].TxID != "shared" {
+		t.Fatalf("expected 5 transactions and one shared transaction (last, lowest height) got: %+v", transactions)
+	}
+	for i := 1; i < len(transactions); i++ {
+		if transactions[i].BlockHeight > transactions[i-1].BlockHeight {
+			t.Fatalf("expected newest first got: %+v", transactions)
+		}
+	}
+}
+
+// TestBatched_Failures tests that failed batches are reported without losing the others
+func TestBatched_Failures(t *testing.T) {
+	t.Parallel()
+
+	t.Run("failed batch", func(t *testing.T) {
+		server, client := newFakeServer(t, nil, batchTransactions(testBatchAddresses(4)))
+		server.FailMatching(http.StatusBadRequest, func(req bitindextest.Request) bool {
+			return strings.Contains(req.Body, "1bad")
+		})
+
+		addresses := append(testBatchAddresses(4), "1bad")
+		utxos, err := bitindex.GetUnspentTransactionsBatched(context.Background(), client, &bitindex.GetUnspentTransactionsRequest{Addresses: addresses}, &bitindex.BatchOptions{BatchSize: 2})
+		if len(utxos) != 5 {
+			t.Fatalf("expected the UTXOs from the 2 good batches got: %d", len(utxos))
+		}
+
+		var batchErr *bitindex.BatchError
+		if !errors.As(err, &batchErr) {
+			t.Fatalf("expected a BatchError got: %v", err)
+		}
+		if batchErr.Batches != 3 || len(batchErr.Failures) != 1 || batchErr.Failures[0].Addresses[0] != "1bad" {
+			t.Fatalf("unexpected failures: %+v", batchErr)
+		}
+		if !errors.Is(err, bitindex.ErrBadRequest) {
+			t.Fatalf("expected ErrBadRequest got: %v", err)
+		}
+
+		transactions, err := bitindex.GetTransactionsBatched(context.Background(), client, &bitindex.GetTransactionsRequest{Addresses: addresses}, &bitindex.BatchOptions{BatchSize: 2})
+		if len(transactions) != 5 || !errors.Is(err, bitindex.ErrBadRequest) {
+			t.Fatalf("expected the transactions from the 2 good batches got: %d %v", len(transactions), err)
+		}
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		_, client := newFakeServer(t, nil, batchTransactions(testBatchAddresses(3)))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		utxos, err := bitindex.GetUnspentTransactionsBatched(ctx, client, &bitindex.GetUnspentTransactionsRequest{Addresses: testBatchAddresses(3)}, nil)
+		if len(utxos) != 0 || !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled got: %d %v", len(utxos), err)
+		}
+	})
+
+	t.Run("no addresses", func(t *testing.T) {
+		_, client := newFakeServer(t, nil)
+
+		utxos, err := bitindex.GetUnspentTransactionsBatched(context.Background(), client, &bitindex.GetUnspentTransactionsRequest{}, nil)
+		if err != nil || len(utxos) != 0 {
+			t.Fatalf("expected nothing got: %d %v", len(utxos), err)
+		}
+	})
+}
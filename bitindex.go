@@ -16,10 +16,12 @@ package bitindex
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"time"
 )
 
 // NewClient creates a new client to submit requests
@@ -35,7 +37,9 @@ func NewClient(apiKey string, network NetworkType, clientOptions *Options) (c *C
 	}
 
 	// Create a client using the given options
-	c = createClient(clientOptions)
+	if c, err = createClient(clientOptions); err != nil {
+		return
+	}
 
 	// Set the key and network
 	c.Parameters.apiKey = apiKey
@@ -46,28 +50,98 @@ func NewClient(apiKey string, network NetworkType, clientOptions *Options) (c *C
 
 // Request is a generic request wrapper that can be used without constraints
 func (c *Client) Request(endpoint string, method string, payload []byte) (response string, err error) {
+	return c.RequestWithContext(context.Background(), endpoint, method, payload)
+}
+
+// RequestWithContext is a generic request wrapper that will be canceled when the given context is done
+//
+// The context is attached to the http request and is checked between retries, so a canceled
+// context (or an expired deadline) stops any further attempts and ctx.Err() is returned
+func (c *Client) RequestWithContext(ctx context.Context, endpoint string, method string, payload []byte) (response string, err error) {
+	var resp *requestResponse
+	if resp, err = c.request(ctx, &apiRequest{
+		Endpoint:   endpoint,
+		Idempotent: isIdempotentMethod(method),
+		Method:     method,
+		Name:       "Request",
+		Payload:    payload,
+	}); err != nil {
+		return
+	}
+	response = string(resp.Body)
+	return
+}
+
+// apiRequest is a single request to the api
+type apiRequest struct {
+	Endpoint   string // is the endpoint to request (IE: addr/address)
+	Idempotent bool   // is true if the request is safe to retry
+	Method     string // is the HTTP method to use
+	Name       string // is the name of the endpoint for the request hooks (IE: AddressInfo)
+	Payload    []byte // is the post data if POST/PUT request
+	Stream     bool   // is true to return the body of a successful response unread (see requestResponse.Stream)
+}
+
+// requestResponse is the result of a single request
+//
+// The status travels with the call (not the client) so the client is safe for concurrent use
+type requestResponse struct {
+	Body       []byte        // is the raw response body
+	Endpoint   string        // is the endpoint requested (IE: addr/address)
+	Method     string        // is the HTTP method used
+	StatusCode int           // is the HTTP status code of the response
+	Stream     io.ReadCloser // is the unread body of a successful streamed request (the caller closes it)
+	URL        string        // is the full url used for the request
+}
+
+// request fires the request (retrying if allowed) and returns the response envelope
+func (c *Client) request(ctx context.Context, req *apiRequest) (response *requestResponse, err error) {
+	for attempt := 0; ; attempt++ {
+
+		// Fire the request
+		response, err = c.fireRequest(ctx, req, attempt)
+
+		// Done, or not safe to retry
+		if !c.shouldRetry(ctx, req, attempt, response, err) {
+			return
+		}
+
+		// Wait before trying again (stops if the context is done)
+		if err = c.waitForRetry(ctx, attempt); err != nil {
+			return
+		}
+	}
+}
+
+// fireRequest fires a single attempt of the request
+func (c *Client) fireRequest(ctx context.Context, req *apiRequest, attempt int) (response *requestResponse, err error) {
+
+	// Stop early if the context is already done
+	if err = ctx.Err(); err != nil {
+		return
+	}
 
 	// Set reader
 	var bodyReader io.Reader
 
-	// Add the network value
-	endpoint = fmt.Sprintf("%s%s/%s", apiEndpoint, c.Parameters.Network, endpoint)
+	// Create the response (add the network value)
+	response = &requestResponse{
+		Endpoint: req.Endpoint,
+		Method:   req.Method,
+		URL:      fmt.Sprintf("%s%s/%s", c.apiEndpoint, c.Parameters.Network, req.Endpoint),
+	}
 
 	// Switch on Methods
-	switch method {
+	switch req.Method {
 	case http.MethodPost, http.MethodPut:
 		{
-			bodyReader = bytes.NewBuffer(payload)
+			bodyReader = bytes.NewBuffer(req.Payload)
 		}
 	}
 
-	// Store for debugging purposes
-	c.LastRequest.Method = method
-	c.LastRequest.URL = endpoint
-
 	// Start the request
 	var request *http.Request
-	if request, err = http.NewRequest(method, endpoint, bodyReader); err != nil {
+	if request, err = http.NewRequestWithContext(ctx, req.Method, response.URL, bodyReader); err != nil {
 		return
 	}
 
@@ -76,31 +150,110 @@ func (c *Client) Request(endpoint string, method string, payload []byte) (respon
 	request.Header.Set(apiKeyField, c.Parameters.apiKey)
 
 	// Set the content type on Method
-	if method == http.MethodPost || method == http.MethodPut {
+	if req.Method == http.MethodPost || req.Method == http.MethodPut {
 		request.Header.Set("Content-Type", "application/json")
 	}
 
+	// Wait for the rate limiter (blocks until allowed or the context is done)
+	if err = c.rateLimiter.Wait(ctx); err != nil {
+		return
+	}
+
+	// Run the hooks before firing
+	info := &RequestInfo{
+		Attempt:      attempt,
+		Endpoint:     req.Endpoint,
+		Method:       req.Method,
+		Name:         req.Name,
+		RequestBytes: len(req.Payload),
+		URL:          response.URL,
+	}
+	c.beforeRequest(ctx, info)
+	start := time.Now()
+
 	// Fire the http request
 	var resp *http.Response
 	if resp, err = c.httpClient.Do(request); err != nil {
+		// Report the context error instead of the transport error
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = ctxErr
+		}
+		info.Error = err
+		info.Latency = time.Since(start)
+		c.onError(ctx, info)
 		return
 	}
 
-	// Close the response body
+	// Close the response body (unless it is streamed to the caller)
 	defer func() {
-		_ = resp.Body.Close()
+		if response.Stream == nil {
+			_ = resp.Body.Close()
+		}
 	}()
 
 	// Save the status
-	c.LastRequest.StatusCode = resp.StatusCode
+	response.StatusCode = resp.StatusCode
+
+	// Back off if the api asks us to (applies to all requests of this client)
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		if delay, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			c.rateLimiter.Pause(delay)
+		}
+	}
+
+	// Store for debugging purposes
+	c.setLastRequest(response, req.Payload)
+
+	// Hand the body of a successful response to the caller (read as it is decoded)
+	if req.Stream && response.StatusCode == http.StatusOK {
+		response.Stream = resp.Body
+		info.Latency = time.Since(start)
+		info.StatusCode = response.StatusCode
+		c.afterResponse(ctx, info)
+		return
+	}
 
 	// Read the body
-	var body []byte
-	if body, err = ioutil.ReadAll(resp.Body); err != nil {
+	if response.Body, err = ioutil.ReadAll(resp.Body); err != nil {
+		info.Error = err
+		info.Latency = time.Since(start)
+		c.onError(ctx, info)
+		return
+	}
+
+	// Run the hooks after the response
+	info.Latency = time.Since(start)
+	info.ResponseBytes = len(response.Body)
+	info.StatusCode = response.StatusCode
+	c.afterResponse(ctx, info)
+	return
+}
+
+// setLastRequest will store a snapshot of the request for debugging purposes
+func (c *Client) setLastRequest(response *requestResponse, payload []byte) {
+	c.lastRequestLock.Lock()
+	defer c.lastRequestLock.Unlock()
+
+	// Tracking is disabled
+	if c.LastRequest == nil {
 		return
 	}
 
-	// Parse the response
-	response = string(body)
+	c.LastRequest.Method = response.Method
+	c.LastRequest.PostData = string(payload)
+	c.LastRequest.StatusCode = response.StatusCode
+	c.LastRequest.URL = response.URL
+}
+
+// GetLastRequest returns a copy of the last request (safe for concurrent use)
+//
+// Returns an empty LastRequest if tracking is disabled (Client.LastRequest is nil)
+func (c *Client) GetLastRequest() (lastRequest LastRequest) {
+	c.lastRequestLock.Lock()
+	defer c.lastRequestLock.Unlock()
+
+	if c.LastRequest != nil {
+		lastRequest = *c.LastRequest
+	}
 	return
 }
@@ -1,24 +1,87 @@
 package bitindex
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/mrz1836/go-bitindex/cassette"
 )
 
 var (
 	testAPIKey = os.Getenv("BITINDEX_API_KEY")
+	testRecord = os.Getenv("BITINDEX_RECORD") == "1"
 )
 
+// newCassetteClient returns a client for the live api tests
+//
+// testdata/cassettes/{test name}.json is replayed if it exists (no network or api key needed),
+// BITINDEX_RECORD=1 records it (needs BITINDEX_API_KEY), otherwise the test hits the live api
+// (skipped in short mode). Every replayed interaction must be used
+func newCassetteClient(t *testing.T, network NetworkType) (*Client, error) {
+	path := filepath.Join("testdata", "cassettes", strings.ReplaceAll(t.Name(), "/", "_")+".json")
+
+	var recorder *cassette.Recorder
+	var err error
+	switch {
+	case testRecord:
+		if len(testAPIKey) == 0 {
+			t.Skip("BITINDEX_API_KEY is required to record cassettes")
+		}
+		if recorder, err = cassette.New(path, cassette.ModeRecord, nil); err != nil {
+			return nil, err
+		}
+		t.Cleanup(func() {
+			if err := recorder.Save(); err != nil {
+				t.Errorf("failed to save the cassette %s: %s", path, err.Error())
+			}
+		})
+	case cassette.Exists(path):
+		if recorder, err = cassette.New(path, cassette.ModeReplay, nil); err != nil {
+			return nil, err
+		}
+		t.Cleanup(func() {
+			if unused := recorder.Unused(); len(unused) > 0 {
+				t.Errorf("%d interaction(s) of the cassette %s were not replayed", len(unused), path)
+			}
+		})
+	case testing.Short():
+		t.Skip("skipping testing in short mode (no cassette)")
+	case len(testAPIKey) == 0:
+		t.Fatalf("no cassette at %s and BITINDEX_API_KEY is not set (record it with BITINDEX_RECORD=1)", path)
+	default:
+		return NewClient(testAPIKey, network, nil)
+	}
+
+	// Replay the interactions in order (no retries)
+	options := ClientDefaultOptions()
+	options.RequestRetryCount = 0
+	options.Transport = recorder
+
+	apiKey := testAPIKey
+	if len(apiKey) == 0 {
+		apiKey = "cassette-replay"
+	}
+	return NewClient(apiKey, network, options)
+}
+
 // TestNewClient test new client
 func TestNewClient(t *testing.T) {
 	// Not testing against bitindex
-	if testing.Short() && len(testAPIKey) == 0 {
-		testAPIKey = "dummy-key-not-testing"
+	apiKey := testAPIKey
+	if len(apiKey) == 0 {
+		apiKey = "dummy-key-not-testing"
 	}
-	client, err := NewClient(testAPIKey, NetworkMain, nil)
+	client, err := NewClient(apiKey, NetworkMain, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -28,6 +91,125 @@ func TestNewClient(t *testing.T) {
 	}
 }
 
+// TestNewClient_APIBaseURL tests setting the api base url and version
+func TestNewClient_APIBaseURL(t *testing.T) {
+	t.Parallel()
+
+	var tests = []struct {
+		baseURL          string
+		apiVersion       string
+		expectedEndpoint string
+		expectedError    bool
+	}{
+		{"", "", "https://api.bitindex.network/api/v3/", false},
+		{"https://indexer.example.com/api/", "v3", "https://indexer.example.com/api/v3/", false},
+		{"http://localhost:3000", "v4", "http://localhost:3000/v4/", false},
+		{"http://localhost:3000/", "/v3/", "http://localhost:3000/v3/", false},
+		{"ftp://indexer.example.com/api/", "v3", "", true},
+		{"indexer.example.com/api/", "v3", "", true},
+		{"https://indexer.example.com/api/?key=value", "v3", "", true},
+		{"https://indexer.example.com/api/", "v3/main", "", true},
+		{"://bad-url", "v3", "", true},
+	}
+
+	for _, test := range tests {
+		options := ClientDefaultOptions()
+		options.APIBaseURL = test.baseURL
+		options.APIVersion = test.apiVersion
+
+		client, err := NewClient("test-key", NetworkMain, options)
+		if test.expectedError {
+			if err == nil {
+				t.Fatalf("%s %s: expected an error", test.baseURL, test.apiVersion)
+			}
+			continue
+		} else if err != nil {
+			t.Fatalf("%s %s: unexpected error: %v", test.baseURL, test.apiVersion, err)
+		}
+
+		if client.apiEndpoint != test.expectedEndpoint {
+			t.Fatalf("expected endpoint: %s got: %s", test.expectedEndpoint, client.apiEndpoint)
+		}
+	}
+}
+
+// countingTransport counts the requests before handing them to the default transport
+type countingTransport struct {
+	mu       sync.Mutex
+	requests int
+}
+
+// RoundTrip counts the request
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.mu.Lock()
+	c.requests++
+	c.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// countingDoer counts the requests before handing them to the default client
+type countingDoer struct {
+	countingTransport
+}
+
+// Do counts the request
+func (c *countingDoer) Do(req *http.Request) (*http.Response, error) {
+	return c.RoundTrip(req)
+}
+
+// TestNewClient_CustomHTTPClient tests providing your own http client, transport or doer
+func TestNewClient_CustomHTTPClient(t *testing.T) {
+	t.Parallel()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"txid":"test"}`))
+	})
+
+	t.Run("transport", func(t *testing.T) {
+		transport := new(countingTransport)
+		options := ClientDefaultOptions()
+		options.Transport = transport
+
+		client := newTestClient(t, handler, options)
+		if _, err := client.GetTransaction("test"); err != nil {
+			t.Fatal(err)
+		}
+		if transport.requests != 1 {
+			t.Fatalf("expected 1 request, got: %d", transport.requests)
+		}
+	})
+
+	t.Run("http client", func(t *testing.T) {
+		transport := new(countingTransport)
+		options := ClientDefaultOptions()
+		options.HTTPClient = &http.Client{Transport: transport}
+		options.Transport = http.DefaultTransport // ignored, the http client has priority
+
+		client := newTestClient(t, handler, options)
+		if _, err := client.GetTransaction("test"); err != nil {
+			t.Fatal(err)
+		}
+		if transport.requests != 1 {
+			t.Fatalf("expected 1 request, got: %d", transport.requests)
+		}
+	})
+
+	t.Run("heimdall doer", func(t *testing.T) {
+		doer := new(countingDoer)
+		options := ClientDefaultOptions()
+		options.HTTPDoer = doer
+		options.HTTPClient = http.DefaultClient // ignored, the doer has priority
+
+		client := newTestClient(t, handler, options)
+		if _, err := client.GetTransaction("test"); err != nil {
+			t.Fatal(err)
+		}
+		if doer.requests != 1 {
+			t.Fatalf("expected 1 request, got: %d", doer.requests)
+		}
+	})
+}
+
 // ExampleNewClient example using NewClient()
 func ExampleNewClient() {
 	client, _ := NewClient("dummy-key", NetworkMain, nil)
@@ -48,6 +230,14 @@ func TestDefaultOptions(t *testing.T) {
 
 	options := ClientDefaultOptions()
 
+	if options.APIBaseURL != defaultAPIBaseURL {
+		t.Fatalf("expected value: %s got: %s", defaultAPIBaseURL, options.APIBaseURL)
+	}
+
+	if options.APIVersion != version {
+		t.Fatalf("expected value: %s got: %s", version, options.APIVersion)
+	}
+
 	if options.UserAgent != defaultUserAgent {
 		t.Fatalf("expected value: %s got: %s", defaultUserAgent, options.UserAgent)
 	}
@@ -56,16 +246,16 @@ func TestDefaultOptions(t *testing.T) {
 		t.Fatalf("expected value: %f got: %f", 2.0, options.BackOffExponentFactor)
 	}
 
-	if options.BackOffInitialTimeout != 2*time.Millisecond {
-		t.Fatalf("expected value: %v got: %v", 2*time.Millisecond, options.BackOffInitialTimeout)
+	if options.BackOffInitialTimeout != 100*time.Millisecond {
+		t.Fatalf("expected value: %v got: %v", 100*time.Millisecond, options.BackOffInitialTimeout)
 	}
 
-	if options.BackOffMaximumJitterInterval != 2*time.Millisecond {
-		t.Fatalf("expected value: %v got: %v", 2*time.Millisecond, options.BackOffMaximumJitterInterval)
+	if options.BackOffMaximumJitterInterval != 50*time.Millisecond {
+		t.Fatalf("expected value: %v got: %v", 50*time.Millisecond, options.BackOffMaximumJitterInterval)
 	}
 
-	if options.BackOffMaxTimeout != 10*time.Millisecond {
-		t.Fatalf("expected value: %v got: %v", 10*time.Millisecond, options.BackOffMaxTimeout)
+	if options.BackOffMaxTimeout != 2*time.Second {
+		t.Fatalf("expected value: %v got: %v", 2*time.Second, options.BackOffMaxTimeout)
 	}
 
 	if options.DialerKeepAlive != 20*time.Second {
@@ -76,6 +266,14 @@ func TestDefaultOptions(t *testing.T) {
 		t.Fatalf("expected value: %v got: %v", 5*time.Second, options.DialerTimeout)
 	}
 
+	if options.RateLimit != 0 {
+		t.Fatalf("expected value: %v got: %v", 0, options.RateLimit)
+	}
+
+	if options.RateLimitBurst != 1 {
+		t.Fatalf("expected value: %v got: %v", 1, options.RateLimitBurst)
+	}
+
 	if options.RequestRetryCount != 2 {
 		t.Fatalf("expected value: %v got: %v", 2, options.RequestRetryCount)
 	}
@@ -100,3 +298,158 @@ func TestDefaultOptions(t *testing.T) {
 		t.Fatalf("expected value: %v got: %v", 5*time.Second, options.TransportTLSHandshakeTimeout)
 	}
 }
+
+// newTestClient will return a client that sends all requests to the given handler
+func newTestClient(t *testing.T, handler http.Handler, options *Options) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	if options == nil {
+		options = ClientDefaultOptions()
+	}
+	options.APIBaseURL = server.URL + "/api/"
+
+	client, err := NewClient("test-key", NetworkMain, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// TestClient_RequestWithContext tests the RequestWithContext()
+func TestClient_RequestWithContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid request", func(t *testing.T) {
+		client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/v3/main/addr/test" {
+				t.Errorf("unexpected path: %s", r.URL.Path)
+			}
+			_, _ = w.Write([]byte(`{"addrStr":"test"}`))
+		}), nil)
+
+		resp, err := client.RequestWithContext(context.Background(), "addr/test", http.MethodGet, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp != `{"addrStr":"test"}` {
+			t.Fatalf("unexpected response: %s", resp)
+		}
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Error("request should not have been sent")
+		}), nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := client.AddressInfoWithContext(ctx, "test")
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got: %v", err)
+		}
+	})
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}), nil)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := client.GetTransactionWithContext(ctx, "test")
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected context.DeadlineExceeded, got: %v", err)
+		}
+	})
+}
+
+// TestClient_ConcurrentRequests tests the client under concurrent use (run with -race)
+func TestClient_ConcurrentRequests(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		address := strings.TrimPrefix(r.URL.Path, "/api/v3/main/addr/")
+		if strings.HasPrefix(address, "missing") {
+			time.Sleep(5 * time.Millisecond)
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"addrStr":"` + address + `"}`))
+	}), nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			// Every other request will fail
+			address := fmt.Sprintf("found-%d", i)
+			if i%2 == 0 {
+				address = fmt.Sprintf("missing-%d", i)
+			}
+
+			info, err := client.AddressInfo(address)
+			if i%2 == 0 {
+				if !errors.Is(err, ErrNotFound) {
+					t.Errorf("%s: expected ErrNotFound, got: %v", address, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", address, err)
+			} else if info.Address != address {
+				t.Errorf("expected address %s, got: %s", address, info.Address)
+			}
+
+			// Reading the snapshot is safe
+			_ = client.GetLastRequest()
+		}(i)
+	}
+	wg.Wait()
+}
+
+// TestClient_GetLastRequest tests the GetLastRequest()
+func TestClient_GetLastRequest(t *testing.T) {
+	t.Parallel()
+
+	t.Run("tracking enabled", func(t *testing.T) {
+		client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"txid":"test"}`))
+		}), nil)
+
+		if _, err := client.GetTransaction("test"); err != nil {
+			t.Fatal(err)
+		}
+
+		lastRequest := client.GetLastRequest()
+		if lastRequest.Method != http.MethodGet {
+			t.Fatalf("unexpected method: %s", lastRequest.Method)
+		}
+		if lastRequest.StatusCode != http.StatusOK {
+			t.Fatalf("unexpected status code: %d", lastRequest.StatusCode)
+		}
+		if !strings.HasSuffix(lastRequest.URL, "/main/tx/test") {
+			t.Fatalf("unexpected url: %s", lastRequest.URL)
+		}
+	})
+
+	t.Run("tracking disabled", func(t *testing.T) {
+		client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"txid":"test"}`))
+		}), nil)
+		client.LastRequest = nil
+
+		if _, err := client.GetTransaction("test"); err != nil {
+			t.Fatal(err)
+		}
+
+		if lastRequest := client.GetLastRequest(); lastRequest.StatusCode != 0 {
+			t.Fatalf("expected an empty snapshot, got: %+v", lastRequest)
+		}
+	})
+}
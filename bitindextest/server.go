@@ -0,0 +1,756 @@
+/*
+Package bitindextest provides a fake BitIndex api server for offline testing
+
+The server implements the v3 routes used by bitindex.Client (addr, addrs/txs, addrs/utxo,
+xpub/*, block, blockheader, rawblock, block-index, status, tx, rawtx, tx/send and webhook/*)
+backed by an in-memory chain state that is programmed by the test:
+
+	server := bitindextest.NewServer()
+	defer server.Close()
+
+	server.AddTransaction(bitindextest.NewTransaction("txid", 100, nil, []bitindextest.Output{
+		{Address: "1Address", Satoshis: 1000},
+	}))
+
+	client, _ := server.NewClient(bitindex.NetworkMain, nil)
+	info, _ := client.AddressInfo("1Address")
+*/
+package bitindextest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mrz1836/go-bitindex"
+)
+
+const (
+	// apiKeyField is the header for the api key
+	apiKeyField = "api_key"
+
+	// defaultPageSize is the default page size for addrs/txs
+	defaultPageSize = 20
+
+	// maxPageSize is the maximum page size for addrs/txs
+	maxPageSize = bitindex.MaxTransactionsPageSize
+)
+
+// Server is a fake BitIndex api server (embeds the httptest.Server)
+type Server struct {
+	*httptest.Server
+
+	APIKey string // if set, requests without this api key are rejected (401)
+
+	blockHashes        map[int64]string                   // are the block hashes by height
+	blocks             map[string]*bitindex.BlockResponse // are the blocks by hash
+	broadcasts         []string                           // are the raw transactions received by tx/send
+	chainInfo          bitindex.ChainInfoResponse         // is the chain info
+	failRules          []failRule                         // are the failures for matching requests
+	failures           []int                              // are the status codes for the next requests
+	inFlight           int                                // is the number of requests being served
+	latency            time.Duration                      // is the delay before each response
+	maxInFlight        int                                // is the most requests served at once
+	monitoredAddresses bitindex.MonitoredAddresses        // are the webhook monitored addresses
+	mu                 sync.RWMutex                       // guards the state
+	rawBlocks          map[string]string                  // are the raw blocks by hash
+	requests           []Request                          // are the requests received
+	tipHash            string                             // is the hash of the highest block
+	transactions       map[string]*bitindex.Transaction   // are the transactions by txid
+	txOrder            []string                           // is the order the transactions were added
+	webhookConfig      bitindex.WebhookConfigResponse     // is the webhook config
+	xpubs              map[string]*xpubState              // are the xpubs
+}
+
+// failRule fails the requests that match with the status code
+type failRule struct {
+	match      func(req Request) bool // returns true for the requests to fail
+	statusCode int                    // is the status code to return
+}
+
+// Request is a request received by the server
+type Request struct {
+	APIKey   string // is the api key header
+	Body     string // is the request body
+	Method   string // is the HTTP method
+	Network  string // is the network (main, test, stn)
+	Path     string // is the route after the network (IE: addr/address)
+	RawQuery string // is the query string
+}
+
+// NewServer starts a new fake BitIndex api server (call Close() when done)
+func NewServer() *Server {
+	s := &Server{
+		blockHashes:  make(map[int64]string),
+		blocks:       make(map[string]*bitindex.BlockResponse),
+		rawBlocks:    make(map[string]string),
+		transactions: make(map[string]*bitindex.Transaction),
+		xpubs:        make(map[string]*xpubState),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// NewClient creates a bitindex.Client that talks to the server (nil options uses the defaults)
+func (s *Server) NewClient(network bitindex.NetworkType, options *bitindex.Options) (*bitindex.Client, error) {
+	if options == nil {
+		options = bitindex.ClientDefaultOptions()
+	}
+	options.APIBaseURL = s.URL + "/api/"
+
+	apiKey := s.APIKey
+	if len(apiKey) == 0 {
+		apiKey = "test-api-key"
+	}
+	return bitindex.NewClient(apiKey, network, options)
+}
+
+// FailNext will fail the next requests with the status codes (in order, IE: 503, 503)
+func (s *Server) FailNext(statusCodes ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, statusCodes...)
+}
+
+// FailMatching will fail every request that matches with the status code (IE: a route or an address)
+func (s *Server) FailMatching(statusCode int, match func(req Request) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failRules = append(s.failRules, failRule{match: match, statusCode: statusCode})
+}
+
+// SetLatency will delay every response (to test timeouts and concurrency)
+func (s *Server) SetLatency(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = latency
+}
+
+// MaxInFlight returns the most requests that were served at once
+func (s *Server) MaxInFlight() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.maxInFlight
+}
+
+// Requests returns the requests received by the server (in order)
+func (s *Server) Requests() []Request {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// serveHTTP records the request and routes it: /api/{version}/{network}/{route}
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+
+	// Read the body
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Split the path
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/api/"), "/", 3)
+	if !strings.HasPrefix(r.URL.Path, "/api/") || len(parts) < 3 {
+		writeError(w, http.StatusNotFound, "route not found: "+r.URL.Path)
+		return
+	}
+	req := Request{
+		APIKey:   r.Header.Get(apiKeyField),
+		Body:     string(body),
+		Method:   r.Method,
+		Network:  parts[1],
+		Path:     parts[2],
+		RawQuery: r.URL.RawQuery,
+	}
+
+	// Record the request and check for a programmed failure
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	var failure int
+	if len(s.failures) > 0 {
+		failure, s.failures = s.failures[0], s.failures[1:]
+	}
+	for _, rule := range s.failRules {
+		if failure == 0 && rule.match(req) {
+			failure = rule.statusCode
+		}
+	}
+	if s.inFlight++; s.inFlight > s.maxInFlight {
+		s.maxInFlight = s.inFlight
+	}
+	latency := s.latency
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.inFlight--
+		s.mu.Unlock()
+	}()
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+
+	if failure > 0 {
+		writeError(w, failure, http.StatusText(failure))
+		return
+	} else if len(s.APIKey) > 0 && req.APIKey != s.APIKey {
+		writeError(w, http.StatusUnauthorized, "invalid api key")
+		return
+	}
+
+	s.route(w, r, &req, body)
+}
+
+// route will call the handler for the route
+func (s *Server) route(w http.ResponseWriter, r *http.Request, req *Request, body []byte) {
+	segments := strings.Split(req.Path, "/")
+	query := r.URL.Query()
+
+	switch {
+	case r.Method == http.MethodGet && len(segments) == 2 && segments[0] == "addr":
+		s.handleAddressInfo(w, segments[1])
+	case r.Method == http.MethodGet && len(segments) == 3 && segments[0] == "addr" && segments[2] == "utxo":
+		s.handleUnspentTransactions(w, []string{segments[1]}, "")
+	case r.Method == http.MethodPost && req.Path == "addrs/txs":
+		s.handleGetTransactions(w, body)
+	case r.Method == http.MethodPost && req.Path == "addrs/utxo":
+		var request bitindex.GetUnspentTransactionsRequest
+		if err := json.Unmarshal(body, &request); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.handleUnspentTransactions(w, splitAddresses(request.Address), query.Get("sort"))
+	case r.Method == http.MethodGet && len(segments) >= 3 && segments[0] == "xpub":
+		s.handleXpub(w, segments[1], strings.Join(segments[2:], "/"), query)
+	case r.Method == http.MethodGet && len(segments) == 2 && segments[0] == "block-index":
+		s.handleBlockIndex(w, segments[1])
+	case r.Method == http.MethodGet && len(segments) == 2 && segments[0] == "block":
+		s.handleBlock(w, segments[1])
+	case r.Method == http.MethodGet && len(segments) == 2 && segments[0] == "blockheader":
+		s.handleBlockHeader(w, segments[1])
+	case r.Method == http.MethodGet && len(segments) == 2 && segments[0] == "rawblock":
+		s.handleRawBlock(w, segments[1])
+	case r.Method == http.MethodGet && req.Path == "status":
+		s.handleStatus(w, query.Get("q"))
+	case r.Method == http.MethodPost && req.Path == "tx/send":
+		s.handleSendTransaction(w, body)
+	case r.Method == http.MethodGet && len(segments) == 2 && segments[0] == "tx":
+		s.handleTransaction(w, segments[1])
+	case r.Method == http.MethodGet && len(segments) == 2 && segments[0] == "rawtx":
+		s.handleRawTransaction(w, segments[1])
+	case req.Path == "webhook/endpoint" && (r.Method == http.MethodGet || r.Method == http.MethodPut):
+		s.handleWebhookConfig(w, r.Method, body)
+	case req.Path == "webhook/monitored_addrs" && (r.Method == http.MethodGet || r.Method == http.MethodPut):
+		s.handleMonitoredAddresses(w, r.Method, body)
+	default:
+		writeError(w, http.StatusNotFound, "route not found: "+r.Method+" "+req.Path)
+	}
+}
+
+// handleAddressInfo returns the address info
+func (s *Server) handleAddressInfo(w http.ResponseWriter, address string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	writeJSON(w, http.StatusOK, s.addressInfo(address))
+}
+
+// handleUnspentTransactions returns the UTXOs for the addresses
+func (s *Server) handleUnspentTransactions(w http.ResponseWriter, addresses []string, sortBy string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	utxos := s.unspentTransactions(addresses)
+	if utxos == nil {
+		utxos = bitindex.UnspentTransactions{}
+	}
+	if err := utxos.Sort(bitindex.UTXOSort(sortBy)); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, utxos)
+}
+
+// handleGetTransactions returns a page of transactions for the addresses
+func (s *Server) handleGetTransactions(w http.ResponseWriter, body []byte) {
+	var request bitindex.GetTransactionsRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	addresses := splitAddresses(request.Address)
+	if len(addresses) == 0 {
+		writeError(w, http.StatusBadRequest, "missing addrs")
+		return
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Only transactions after the height (or block hash)
+	var afterHeight int64
+	if len(request.AfterHeight) > 0 {
+		var err error
+		if afterHeight, err = strconv.ParseInt(request.AfterHeight, 10, 64); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid afterHeight")
+			return
+		}
+	}
+	if len(request.AfterBlockHash) > 0 {
+		block, ok := s.blocks[request.AfterBlockHash]
+		if !ok {
+			writeError(w, http.StatusNotFound, "block not found")
+			return
+		}
+		afterHeight = block.Height
+	}
+
+	var items []bitindex.Transaction
+	for _, tx := range s.addressTransactions(addresses) {
+		if afterHeight > 0 && tx.BlockHeight > 0 && tx.BlockHeight <= afterHeight {
+			continue
+		}
+		if !request.IncludeHex {
+			tx.RawTx = ""
+		}
+		items = append(items, *tx)
+	}
+
+	// Page the results [from, to)
+	from, to := request.FromIndex, request.ToIndex
+	if to <= 0 {
+		to = from + defaultPageSize
+	}
+	if from < 0 || to <= from {
+		writeError(w, http.StatusBadRequest, "invalid fromIndex or toIndex")
+		return
+	} else if to-from > maxPageSize {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("page size must be at most %d", maxPageSize))
+		return
+	}
+	total := int64(len(items))
+	if from > total {
+		from = total
+	}
+	if to > total {
+		to = total
+	}
+
+	page := append([]bitindex.Transaction{}, items[from:to]...)
+	writeJSON(w, http.StatusOK, &bitindex.GetTransactionsResponse{
+		From:       from,
+		Items:      page,
+		To:         to,
+		TotalItems: total,
+	})
+}
+
+// handleXpub routes the xpub requests
+func (s *Server) handleXpub(w http.ResponseWriter, xPub, route string, query map[string][]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.xpubs[xPub]
+	if !ok {
+		writeError(w, http.StatusNotFound, "xpub not found")
+		return
+	}
+
+	get := func(key string) string {
+		if values := query[key]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	switch route {
+	case "addrs/next":
+		s.handleXpubNextAddress(w, state, get("reserveTime"))
+	case "addrs":
+		s.handleXpubAddresses(w, state, get("offset"), get("limit"), get("order"), get("address"))
+	case "status":
+		balance := &bitindex.XpubBalance{}
+		for _, utxo := range s.unspentTransactions(s.xpubAddressList(state)) {
+			if utxo.Height > 0 {
+				balance.Confirmed += utxo.Satoshis.Int64()
+			} else {
+				balance.UnConfirmed += utxo.Satoshis.Int64()
+			}
+		}
+		writeJSON(w, http.StatusOK, balance)
+	case "utxo":
+		utxos := s.unspentTransactions(s.xpubAddressList(state))
+		if utxos == nil {
+			utxos = bitindex.UnspentTransactions{}
+		}
+		for i := range utxos {
+			address, _ := xpubAddress(state, utxos[i].Address)
+			utxos[i].Chain, utxos[i].Num, utxos[i].Path = address.Chain, address.Num, address.Path
+		}
+		if err := utxos.Sort(bitindex.UTXOSort(get("sort"))); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, utxos)
+	case "txs":
+		transactions := bitindex.XpubAddresses{}
+		for _, tx := range s.addressTransactions(s.xpubAddressList(state)) {
+			for _, address := range state.addresses {
+				if involvesAddress(tx, address.Address) {
+					address.Height, address.TxID = tx.BlockHeight, tx.TxID
+					transactions = append(transactions, address)
+				}
+			}
+		}
+		writeJSON(w, http.StatusOK, transactions)
+	default:
+		writeError(w, http.StatusNotFound, "route not found: xpub/"+route)
+	}
+}
+
+// handleXpubNextAddress returns the next unused (and not reserved) receive address (lock must be held)
+func (s *Server) handleXpubNextAddress(w http.ResponseWriter, state *xpubState, reserveTime string) {
+	var reserve time.Duration
+	if len(reserveTime) > 0 {
+		seconds, err := strconv.Atoi(reserveTime)
+		if err != nil || seconds < 0 {
+			writeError(w, http.StatusBadRequest, "invalid reserveTime")
+			return
+		}
+		reserve = time.Duration(seconds) * time.Second
+	}
+
+	now := time.Now()
+	for _, address := range state.addresses {
+		if address.Chain != 0 || now.Before(state.reserved[address.Address]) ||
+			len(s.addressTransactions([]string{address.Address})) > 0 {
+			continue
+		}
+		if reserve > 0 {
+			state.reserved[address.Address] = now.Add(reserve)
+		}
+		writeJSON(w, http.StatusOK, bitindex.XpubAddresses{address})
+		return
+	}
+	writeError(w, http.StatusNotFound, "no unused addresses")
+}
+
+// handleXpubAddresses returns the xpub addresses (lock must be held)
+func (s *Server) handleXpubAddresses(w http.ResponseWriter, state *xpubState, offset, limit, order, filterByAddress string) {
+	start, err := parseOptionalInt(offset, 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid offset")
+		return
+	}
+	size, err := parseOptionalInt(limit, len(state.addresses))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid limit")
+		return
+	}
+
+	addresses := bitindex.XpubAddresses{}
+	for _, address := range state.addresses {
+		if len(filterByAddress) == 0 || address.Address == filterByAddress {
+			addresses = append(addresses, address)
+		}
+	}
+	if strings.EqualFold(order, "desc") {
+		for i, j := 0, len(addresses)-1; i < j; i, j = i+1, j-1 {
+			addresses[i], addresses[j] = addresses[j], addresses[i]
+		}
+	}
+
+	if start > len(addresses) {
+		start = len(addresses)
+	}
+	end := start + size
+	if end > len(addresses) {
+		end = len(addresses)
+	}
+	writeJSON(w, http.StatusOK, addresses[start:end])
+}
+
+// handleBlockIndex returns the block hash for the height
+func (s *Server) handleBlockIndex(w http.ResponseWriter, heightValue string) {
+	height, err := strconv.ParseInt(heightValue, 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid height")
+		return
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	hash, ok := s.blockHashes[height]
+	if !ok {
+		writeError(w, http.StatusNotFound, "block not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, &bitindex.BlockHashByHeightResponse{BlockHash: hash})
+}
+
+// block returns a copy of the block with derived fields (lock must be held)
+func (s *Server) block(hash string) (*bitindex.BlockResponse, bool) {
+	block, ok := s.blocks[hash]
+	if !ok {
+		return nil, false
+	}
+	result := *block
+	result.Confirmations = s.tipHeight() - block.Height + 1
+	if next, found := s.blockHashes[block.Height+1]; found && len(result.NextBlockHash) == 0 {
+		result.NextBlockHash = next
+	}
+	return &result, true
+}
+
+// handleBlock returns the block
+func (s *Server) handleBlock(w http.ResponseWriter, hash string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	block, ok := s.block(hash)
+	if !ok {
+		writeError(w, http.StatusNotFound, "block not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, block)
+}
+
+// handleBlockHeader returns the block header (the block without the transactions)
+func (s *Server) handleBlockHeader(w http.ResponseWriter, hash string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	block, ok := s.block(hash)
+	if !ok {
+		writeError(w, http.StatusNotFound, "block not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, &bitindex.BlockHeaderResponse{
+		Bits:              block.Bits,
+		ChainWork:         block.ChainWork,
+		Confirmations:     block.Confirmations,
+		Difficulty:        block.Difficulty,
+		Hash:              block.Hash,
+		Height:            block.Height,
+		MedianTime:        block.MedianTime,
+		MerkleRoot:        block.MerkleRoot,
+		NextBlockHash:     block.NextBlockHash,
+		Nonce:             block.Nonce,
+		PreviousBlockHash: block.PreviousBlockHash,
+		Time:              block.Time,
+		Version:           block.Version,
+		VersionHex:        block.VersionHex,
+	})
+}
+
+// handleRawBlock returns the raw block
+func (s *Server) handleRawBlock(w http.ResponseWriter, hash string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rawBlock, ok := s.rawBlocks[hash]
+	if !ok {
+		writeError(w, http.StatusNotFound, "block not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, &bitindex.BlockRawResponse{RawBlock: rawBlock})
+}
+
+// handleStatus returns the chain status for the query
+func (s *Server) handleStatus(w http.ResponseWriter, query string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	switch query {
+	case "chainInfo":
+		info := s.chainInfo
+		if tip := s.tipHeight(); tip > info.Info.Blocks {
+			info.Info.Blocks = tip
+		}
+		writeJSON(w, http.StatusOK, &info)
+	case "getDifficulty":
+		difficulty := s.chainInfo.Info.Difficulty
+		if block, ok := s.blocks[s.tipHash]; ok && difficulty == 0 {
+			difficulty = block.Difficulty
+		}
+		writeJSON(w, http.StatusOK, &bitindex.ChainDifficultyResponse{Difficulty: difficulty})
+	case "getBestBlockHash":
+		writeJSON(w, http.StatusOK, &bitindex.ChainBestBlockHashResponse{BestBlockHash: s.tipHash})
+	case "getLastBlockHash":
+		writeJSON(w, http.StatusOK, &bitindex.ChainLastBlockHashResponse{LastBlockHash: s.tipHash, SyncTipHash: s.tipHash})
+	default:
+		writeError(w, http.StatusBadRequest, "invalid query: "+query)
+	}
+}
+
+// handleTransaction returns the transaction
+func (s *Server) handleTransaction(w http.ResponseWriter, txID string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tx, ok := s.transaction(txID)
+	if !ok {
+		writeError(w, http.StatusNotFound, "transaction not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, tx)
+}
+
+// handleRawTransaction returns the raw transaction hex
+func (s *Server) handleRawTransaction(w http.ResponseWriter, txID string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tx, ok := s.transactions[txID]
+	if !ok || len(tx.RawTx) == 0 {
+		writeError(w, http.StatusNotFound, "transaction not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, &bitindex.TransactionRaw{RawTx: tx.RawTx})
+}
+
+// handleSendTransaction accepts the raw transaction and adds it to the mempool (unconfirmed)
+func (s *Server) handleSendTransaction(w http.ResponseWriter, body []byte) {
+	var request struct {
+		RawTx string `json:"rawtx"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		writeJSON(w, http.StatusBadRequest, &bitindex.APIErrorResponse{Error: err.Error(), ErrorCode: http.StatusBadRequest})
+		return
+	}
+	rawTx, err := hex.DecodeString(request.RawTx)
+	if err != nil || len(rawTx) == 0 {
+		writeJSON(w, http.StatusBadRequest, &bitindex.APIErrorResponse{Error: "TX decode failed", ErrorCode: http.StatusBadRequest})
+		return
+	}
+
+	// The txid is the reversed double sha256 of the raw tx
+	first := sha256.Sum256(rawTx)
+	hash := sha256.Sum256(first[:])
+	for i, j := 0, len(hash)-1; i < j; i, j = i+1, j-1 {
+		hash[i], hash[j] = hash[j], hash[i]
+	}
+	txID := hex.EncodeToString(hash[:])
+
+	s.mu.Lock()
+	s.broadcasts = append(s.broadcasts, request.RawTx)
+	if _, ok := s.transactions[txID]; !ok {
+		s.transactions[txID] = &bitindex.Transaction{RawTx: request.RawTx, TxID: txID}
+		s.txOrder = append(s.txOrder, txID)
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, &bitindex.SendTransactionResponse{TxID: txID})
+}
+
+// handleWebhookConfig gets or updates the webhook config
+func (s *Server) handleWebhookConfig(w http.ResponseWriter, method string, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if method == http.MethodPut {
+		var update bitindex.WebhookUpdateConfig
+		if err := json.Unmarshal(body, &update); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.webhookConfig.Enabled = update.Enabled
+		if len(update.Secret) > 0 {
+			s.webhookConfig.Secret = update.Secret
+		}
+		if len(update.URL) > 0 {
+			s.webhookConfig.URL = update.URL
+		}
+	}
+	if len(s.webhookConfig.ID) == 0 {
+		s.webhookConfig.ID = "webhook-1"
+	}
+	writeJSON(w, http.StatusOK, &s.webhookConfig)
+}
+
+// handleMonitoredAddresses gets or adds the monitored addresses
+func (s *Server) handleMonitoredAddresses(w http.ResponseWriter, method string, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if method == http.MethodPut {
+		var addresses bitindex.MonitoredAddresses
+		if err := json.Unmarshal(body, &addresses); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		for _, address := range addresses {
+			found := false
+			for _, existing := range s.monitoredAddresses {
+				if existing.Address == address.Address {
+					found = true
+					break
+				}
+			}
+			if !found {
+				s.monitoredAddresses = append(s.monitoredAddresses, address)
+			}
+		}
+	}
+	addresses := append(bitindex.MonitoredAddresses{}, s.monitoredAddresses...)
+	writeJSON(w, http.StatusOK, addresses)
+}
+
+// splitAddresses splits the comma separated addresses
+func splitAddresses(value string) (addresses []string) {
+	for _, address := range strings.Split(value, ",") {
+		if address = strings.TrimSpace(address); len(address) > 0 {
+			addresses = append(addresses, address)
+		}
+	}
+	return
+}
+
+// parseOptionalInt parses a non-negative int or returns the default if empty
+func parseOptionalInt(value string, defaultValue int) (int, error) {
+	if len(value) == 0 {
+		return defaultValue, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("invalid number: %s", value)
+	}
+	return number, nil
+}
+
+// writeJSON writes the value as JSON with the status code
+func writeJSON(w http.ResponseWriter, statusCode int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// writeError writes an api error in the BitIndex format
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	name := "Error"
+	switch statusCode {
+	case http.StatusBadRequest:
+		name = "BadRequestError"
+	case http.StatusUnauthorized:
+		name = "UnauthorizedError"
+	case http.StatusNotFound:
+		name = "NotFoundError"
+	case http.StatusTooManyRequests:
+		name = "TooManyRequestsError"
+	}
+	writeJSON(w, statusCode, &bitindex.APIInternalError{
+		Errors:       []string{message},
+		ErrorMessage: message,
+		ErrorName:    name,
+	})
+}
@@ -0,0 +1,427 @@
+package bitindextest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mrz1836/go-bitindex"
+)
+
+const (
+	testAddress       = "1GenocdBuCJLsBB9ysatg3QW12SHqbRMB5"
+	testAddressChange = "1LZAp2NHpF4K6YrGxWvRGNaNpKPMBJd6bb"
+	testAddressOther  = "16ZqP5Tb22KJuvSAbjNkoiZs13mmRmexZA"
+	testBlockHash     = "0000000000000000019bd0a0ff1e32b0fda2a5e3b3b4a12e8a2d1e52b1a1e0d1"
+	testBlockHashNext = "00000000000000000123b5c5d2a8f0a0bb2c0e0d7c1cfbf1d0e4d4a2d2a9c4f2"
+	testRawTx         = "01000000010000000000000000000000000000000000000000000000000000000000000000ffffffff00ffffffff0100f2052a010000001976a914000000000000000000000000000000000000000088ac00000000"
+	testRawTxID       = "a0792787fc14442386f67a9b74022c73a13494ae00b985393d733e3c686268e0"
+	testXpub          = "xpub6CUGRUonZSQ4TWtTMmzXdrXDtypWKiKrhko4egpiMZbpiaQL2jkwSB1icqYh2cfDfVxdx4df189oLKnC5fSwqPfgyP3hooxujYzAu3fDVmz"
+)
+
+// newTestServer returns a server with a funded address, a spend and two blocks
+//
+// tx1 (height 100): 10,000 sats to testAddress
+// tx2 (height 101): spends tx1:0 -> 6,000 to testAddressOther + 3,500 change to testAddressChange
+// tx3 (unconfirmed): 2,000 sats to testAddress
+func newTestServer(t *testing.T) (*Server, *bitindex.Client) {
+	server := NewServer()
+	t.Cleanup(server.Close)
+
+	server.AddBlock(bitindex.BlockResponse{Hash: testBlockHash, Height: 100, Difficulty: 1.5, Tx: []string{"tx1"}}, "00ff")
+	server.AddBlock(bitindex.BlockResponse{Hash: testBlockHashNext, Height: 101, PreviousBlockHash: testBlockHash, Tx: []string{"tx2"}}, "")
+	server.AddTransaction(NewTransaction("tx1", 100, nil, []Output{{Address: testAddress, Satoshis: 10000}}))
+	server.AddTransaction(NewTransaction("tx2", 101,
+		[]Input{{Address: testAddress, Satoshis: 10000, TxID: "tx1", Vout: 0}},
+		[]Output{{Address: testAddressOther, Satoshis: 6000}, {Address: testAddressChange, Satoshis: 3500}},
+	))
+	server.AddTransaction(NewTransaction("tx3", 0, nil, []Output{{Address: testAddress, Satoshis: 2000}}))
+
+	client, err := server.NewClient(bitindex.NetworkMain, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server, client
+}
+
+// TestServer_AddressInfo tests the derived address info
+func TestServer_AddressInfo(t *testing.T) {
+	t.Parallel()
+	_, client := newTestServer(t)
+
+	info, err := client.AddressInfo(testAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Address != testAddress {
+		t.Fatalf("expected address: %s got: %s", testAddress, info.Address)
+	} else if info.BalanceSatoshis != 0 || info.UnconfirmedBalanceSatoshis != 2000 {
+		t.Fatalf("expected balance 0 (unconfirmed 2000) got: %d (%d)", info.BalanceSatoshis, info.UnconfirmedBalanceSatoshis)
+	} else if info.TotalReceivedSatoshis != 12000 || info.TotalSentSatoshis != 10000 {
+		t.Fatalf("expected received 12000 sent 10000 got: %d %d", info.TotalReceivedSatoshis, info.TotalSentSatoshis)
+	} else if info.TxAppearances != 2 || info.UnconfirmedTxAppearances != 1 {
+		t.Fatalf("expected 2 (1 unconfirmed) appearances got: %d (%d)", info.TxAppearances, info.UnconfirmedTxAppearances)
+	} else if len(info.Transactions) != 3 || info.Transactions[0] != "tx3" {
+		t.Fatalf("expected 3 transactions (newest first) got: %v", info.Transactions)
+	}
+}
+
+// TestServer_UnspentTransactions tests the UTXO routes
+func TestServer_UnspentTransactions(t *testing.T) {
+	t.Parallel()
+	_, client := newTestServer(t)
+
+	// Spent outputs are excluded
+	utxos, err := client.AddressUnspentTransactions(testAddress)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(utxos) != 1 || utxos[0].TxID != "tx3" || utxos[0].Satoshis != 2000 {
+		t.Fatalf("expected the unconfirmed utxo got: %+v", utxos)
+	}
+
+	// Multiple addresses, sorted
+	utxos, err = client.GetUnspentTransactions(&bitindex.GetUnspentTransactionsRequest{
+		Addresses: []string{testAddressOther, testAddressChange},
+		Sort:      "value:desc",
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(utxos) != 2 || utxos[0].Satoshis != 6000 || utxos[1].Satoshis != 3500 {
+		t.Fatalf("expected 2 utxos sorted by value got: %+v", utxos)
+	} else if utxos[0].Confirmations != 1 || utxos[0].Height != 101 {
+		t.Fatalf("expected 1 confirmation at 101 got: %d at %d", utxos[0].Confirmations, utxos[0].Height)
+	}
+}
+
+// TestServer_GetTransactions tests the paging and filters of addrs/txs
+func TestServer_GetTransactions(t *testing.T) {
+	t.Parallel()
+	_, client := newTestServer(t)
+
+	resp, err := client.GetTransactions(&bitindex.GetTransactionsRequest{Address: testAddress, ToIndex: 2})
+	if err != nil {
+		t.Fatal(err)
+	} else if resp.TotalItems != 3 || resp.From != 0 || resp.To != 2 || len(resp.Items) != 2 {
+		t.Fatalf("unexpected page: total %d from %d to %d items %d", resp.TotalItems, resp.From, resp.To, len(resp.Items))
+	} else if resp.Items[0].TxID != "tx3" || resp.Items[1].TxID != "tx2" {
+		t.Fatalf("expected newest first got: %s %s", resp.Items[0].TxID, resp.Items[1].TxID)
+	}
+
+	// The spent output is marked
+	if resp.Items[1].Vin[0].TxID != "tx1" {
+		t.Fatalf("expected tx2 to spend tx1 got: %s", resp.Items[1].Vin[0].TxID)
+	}
+
+	// After the first block
+	resp, err = client.GetTransactions(&bitindex.GetTransactionsRequest{Address: testAddress, AfterBlockHash: testBlockHash})
+	if err != nil {
+		t.Fatal(err)
+	} else if resp.TotalItems != 2 {
+		t.Fatalf("expected 2 transactions after the block got: %d", resp.TotalItems)
+	}
+
+	// Page size is capped
+	_, err = client.GetTransactions(&bitindex.GetTransactionsRequest{Address: testAddress, ToIndex: maxPageSize + 1})
+	if !errors.Is(err, bitindex.ErrBadRequest) {
+		t.Fatalf("expected ErrBadRequest got: %v", err)
+	}
+}
+
+// TestServer_Transactions tests the tx, rawtx & tx/send routes
+func TestServer_Transactions(t *testing.T) {
+	t.Parallel()
+	server, client := newTestServer(t)
+
+	tx, err := client.GetTransaction("tx1")
+	if err != nil {
+		t.Fatal(err)
+	} else if tx.Confirmations != 2 || tx.Vout[0].SpentTxID != "tx2" {
+		t.Fatalf("expected 2 confirmations and spent by tx2 got: %d %s", tx.Confirmations, tx.Vout[0].SpentTxID)
+	}
+
+	if _, err = client.GetTransaction("missing"); !errors.Is(err, bitindex.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound got: %v", err)
+	}
+
+	// Broadcast, then read it back
+	var sent *bitindex.SendTransactionResponse
+	if sent, err = client.SendTransaction(testRawTx); err != nil {
+		t.Fatal(err)
+	} else if sent.TxID != testRawTxID {
+		t.Fatalf("expected txid: %s got: %s", testRawTxID, sent.TxID)
+	} else if broadcasts := server.Broadcasts(); len(broadcasts) != 1 || broadcasts[0] != testRawTx {
+		t.Fatalf("expected the broadcast to be recorded got: %v", broadcasts)
+	}
+
+	var raw *bitindex.TransactionRaw
+	if raw, err = client.GetTransactionRaw(testRawTxID); err != nil {
+		t.Fatal(err)
+	} else if raw.RawTx != testRawTx {
+		t.Fatalf("expected raw tx: %s got: %s", testRawTx, raw.RawTx)
+	}
+
+	// Invalid hex
+	if _, err = client.SendTransaction("not-hex"); !errors.Is(err, bitindex.ErrBadRequest) {
+		t.Fatalf("expected ErrBadRequest got: %v", err)
+	}
+}
+
+// TestServer_Blocks tests the block routes
+func TestServer_Blocks(t *testing.T) {
+	t.Parallel()
+	_, client := newTestServer(t)
+
+	hash, err := client.GetBlockHashByHeight(100)
+	if err != nil {
+		t.Fatal(err)
+	} else if hash.BlockHash != testBlockHash {
+		t.Fatalf("expected hash: %s got: %s", testBlockHash, hash.BlockHash)
+	}
+
+	var block *bitindex.BlockResponse
+	if block, err = client.GetBlock(testBlockHash); err != nil {
+		t.Fatal(err)
+	} else if block.Confirmations != 2 || block.NextBlockHash != testBlockHashNext {
+		t.Fatalf("expected 2 confirmations and the next hash got: %d %s", block.Confirmations, block.NextBlockHash)
+	}
+
+	var header *bitindex.BlockHeaderResponse
+	if header, err = client.GetBlockHeader(testBlockHashNext); err != nil {
+		t.Fatal(err)
+	} else if header.Height != 101 || header.PreviousBlockHash != testBlockHash {
+		t.Fatalf("unexpected header: %+v", header)
+	}
+
+	var raw *bitindex.BlockRawResponse
+	if raw, err = client.GetBlockRaw(testBlockHash); err != nil {
+		t.Fatal(err)
+	} else if raw.RawBlock != "00ff" {
+		t.Fatalf("expected raw block 00ff got: %s", raw.RawBlock)
+	}
+
+	if _, err = client.GetBlockHashByHeight(999); !errors.Is(err, bitindex.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound got: %v", err)
+	}
+}
+
+// TestServer_Chain tests the status routes
+func TestServer_Chain(t *testing.T) {
+	t.Parallel()
+	server, client := newTestServer(t)
+
+	var info bitindex.ChainInfoResponse
+	info.Info.Network = "livenet"
+	server.SetChainInfo(info)
+
+	chainInfo, err := client.ChainInfo()
+	if err != nil {
+		t.Fatal(err)
+	} else if chainInfo.Info.Blocks != 101 || chainInfo.Info.Network != "livenet" {
+		t.Fatalf("unexpected chain info: %+v", chainInfo.Info)
+	}
+
+	var best *bitindex.ChainBestBlockHashResponse
+	if best, err = client.ChainBestBlockHash(); err != nil {
+		t.Fatal(err)
+	} else if best.BestBlockHash != testBlockHashNext {
+		t.Fatalf("expected best hash: %s got: %s", testBlockHashNext, best.BestBlockHash)
+	}
+
+	var last *bitindex.ChainLastBlockHashResponse
+	if last, err = client.ChainLastBlockHash(); err != nil {
+		t.Fatal(err)
+	} else if last.LastBlockHash != testBlockHashNext || last.SyncTipHash != testBlockHashNext {
+		t.Fatalf("unexpected last block hash: %+v", last)
+	}
+
+	var difficulty *bitindex.ChainDifficultyResponse
+	if difficulty, err = client.ChainDifficulty(); err != nil {
+		t.Fatal(err)
+	} else if difficulty.Difficulty != 0 {
+		t.Fatalf("expected difficulty of the tip (0) got: %f", difficulty.Difficulty)
+	}
+}
+
+// TestServer_Xpub tests the xpub routes
+func TestServer_Xpub(t *testing.T) {
+	t.Parallel()
+	server, client := newTestServer(t)
+
+	server.AddXpub(testXpub,
+		bitindex.XPubAddress{Address: testAddress, Chain: 0, Num: 0, Path: "0/0"},
+		bitindex.XPubAddress{Address: testAddressChange, Chain: 1, Num: 0, Path: "1/0"},
+		bitindex.XPubAddress{Address: "1NextUnusedAddress", Chain: 0, Num: 1, Path: "0/1"},
+		bitindex.XPubAddress{Address: "1AfterThatAddress", Chain: 0, Num: 2, Path: "0/2"},
+	)
+
+	// Next unused address, reserved
+	next, err := client.GetXpubNextAddress(testXpub, 60)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(next) != 1 || next[0].Address != "1NextUnusedAddress" {
+		t.Fatalf("expected the first unused address got: %+v", next)
+	}
+	if next, err = client.GetXpubNextAddress(testXpub, 0); err != nil {
+		t.Fatal(err)
+	} else if next[0].Address != "1AfterThatAddress" {
+		t.Fatalf("expected the reserved address to be skipped got: %+v", next)
+	}
+
+	// Addresses
+	var addresses bitindex.XpubAddresses
+	if addresses, err = client.GetXpubAddressesPage(testXpub, &bitindex.GetXpubAddressesOptions{Limit: 2, Offset: 1, Order: bitindex.SortDescending}); err != nil {
+		t.Fatal(err)
+	} else if len(addresses) != 2 || addresses[0].Address != "1AfterThatAddress" || addresses[1].Address != "1NextUnusedAddress" {
+		t.Fatalf("unexpected addresses: %+v", addresses)
+	}
+
+	// Balance
+	var balance *bitindex.XpubBalance
+	if balance, err = client.GetXpubBalance(testXpub); err != nil {
+		t.Fatal(err)
+	} else if balance.Confirmed != 3500 || balance.UnConfirmed != 2000 {
+		t.Fatalf("expected 3500 (2000 unconfirmed) got: %d (%d)", balance.Confirmed, balance.UnConfirmed)
+	}
+
+	// UTXOs have the path
+	var utxos bitindex.UnspentTransactions
+	if utxos, err = client.GetXpubUnspentTransactionsSorted(testXpub, "value:asc"); err != nil {
+		t.Fatal(err)
+	} else if len(utxos) != 2 || utxos[0].Path != "0/0" || utxos[1].Path != "1/0" {
+		t.Fatalf("unexpected utxos: %+v", utxos)
+	}
+
+	// Transactions
+	var transactions bitindex.XpubAddresses
+	if transactions, err = client.GetXpubTransactions(testXpub); err != nil {
+		t.Fatal(err)
+	} else if len(transactions) != 4 || transactions[0].TxID != "tx3" {
+		t.Fatalf("unexpected transactions: %+v", transactions)
+	}
+
+	if _, err = client.GetXpubBalance("unknown"); !errors.Is(err, bitindex.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound got: %v", err)
+	}
+}
+
+// TestServer_Webhook tests the webhook routes
+func TestServer_Webhook(t *testing.T) {
+	t.Parallel()
+	server, client := newTestServer(t)
+
+	config, err := client.UpdateWebhookConfig(&bitindex.WebhookUpdateConfig{Enabled: true, URL: "https://example.com/hook"})
+	if err != nil {
+		t.Fatal(err)
+	} else if !config.Enabled || config.URL != "https://example.com/hook" || len(config.ID) == 0 {
+		t.Fatalf("unexpected config: %+v", config)
+	}
+	if config, err = client.GetWebhookConfig(); err != nil {
+		t.Fatal(err)
+	} else if config.URL != "https://example.com/hook" {
+		t.Fatalf("expected the updated config got: %+v", config)
+	}
+
+	addresses, err := client.AddMonitoredAddresses(&bitindex.MonitoredAddresses{{Address: testAddress}, {Address: testAddress}})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(addresses) != 1 || addresses[0].Address != testAddress {
+		t.Fatalf("expected one monitored address got: %+v", addresses)
+	}
+	if addresses, err = client.GetMonitoredAddresses(); err != nil {
+		t.Fatal(err)
+	} else if len(addresses) != 1 || len(server.MonitoredAddresses()) != 1 {
+		t.Fatalf("expected one monitored address got: %+v", addresses)
+	}
+}
+
+// TestServer_APIKeyAndFailures tests the api key check, programmed failures and recorded requests
+func TestServer_APIKeyAndFailures(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer()
+	defer server.Close()
+	server.APIKey = "secret"
+
+	// Wrong key
+	wrong, err := bitindex.NewClient("wrong", bitindex.NetworkTest, &bitindex.Options{APIBaseURL: server.URL + "/api/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = wrong.AddressInfo(testAddress); !errors.Is(err, bitindex.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized got: %v", err)
+	}
+
+	// Transient failures are retried by the client
+	options := bitindex.ClientDefaultOptions()
+	options.BackOffInitialTimeout = time.Millisecond
+	options.BackOffMaxTimeout = 2 * time.Millisecond
+	var client *bitindex.Client
+	if client, err = server.NewClient(bitindex.NetworkTest, options); err != nil {
+		t.Fatal(err)
+	}
+	server.FailNext(503, 503)
+	if _, err = client.AddressInfo(testAddress); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := server.Requests()
+	if len(requests) != 4 {
+		t.Fatalf("expected 4 requests got: %d", len(requests))
+	}
+	last := requests[len(requests)-1]
+	if last.APIKey != "secret" || last.Network != "test" || last.Path != "addr/"+testAddress {
+		t.Fatalf("unexpected request: %+v", last)
+	}
+}
+
+// TestServer_FailMatching tests failing the matching requests, the latency and the requests in flight
+func TestServer_FailMatching(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newTestServer(t)
+	options := bitindex.ClientDefaultOptions()
+	options.RequestRetryCount = 0
+	client, err := server.NewClient(bitindex.NetworkMain, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.FailMatching(http.StatusTooManyRequests, func(req Request) bool {
+		return req.Path == "addr/"+testAddressOther
+	})
+	server.SetLatency(20 * time.Millisecond)
+
+	if _, err = client.AddressInfo(testAddress); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = client.AddressInfo(testAddressChange)
+		}(i)
+	}
+	wg.Wait()
+	for _, err = range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if server.MaxInFlight() < 2 {
+		t.Fatalf("expected concurrent requests got: %d", server.MaxInFlight())
+	}
+
+	// Every matching request fails (not only the next)
+	server.SetLatency(0)
+	for i := 0; i < 2; i++ {
+		if _, err = client.AddressInfoWithContext(context.Background(), testAddressOther); !errors.Is(err, bitindex.ErrRateLimited) {
+			t.Fatalf("expected ErrRateLimited got: %v", err)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package bitindextest
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/mrz1836/go-bitindex"
+)
+
+// xpubState is the programmable state for a single xpub
+type xpubState struct {
+	addresses bitindex.XpubAddresses // are the derived addresses (chain, num, path)
+	reserved  map[string]time.Time   // are the reserved addresses (until)
+}
+
+// AddTransaction adds (or replaces) a transaction in the chain state
+//
+// The address history, balances and UTXOs are derived from the transactions: an output is
+// unspent until another transaction spends it (vin txid + vout). Amounts can be set in either
+// BSV (Value) or satoshis (ValueSatoshis), confirmations are derived from the block height
+func (s *Server) AddTransaction(tx bitindex.Transaction) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Copy the inputs and outputs (the caller keeps theirs)
+	tx.Vin = append(tx.Vin[:0:0], tx.Vin...)
+	tx.Vout = append(tx.Vout[:0:0], tx.Vout...)
+
+	// Normalize the amounts
+	for i := range tx.Vin {
+		tx.Vin[i].Value, tx.Vin[i].ValueSatoshis = normalizeAmount(tx.Vin[i].Value, tx.Vin[i].ValueSatoshis)
+		if len(tx.Vin[i].AddressAddr) == 0 {
+			tx.Vin[i].AddressAddr = tx.Vin[i].Address
+		} else if len(tx.Vin[i].Address) == 0 {
+			tx.Vin[i].Address = tx.Vin[i].AddressAddr
+		}
+	}
+	for i := range tx.Vout {
+		tx.Vout[i].Value, tx.Vout[i].ValueSatoshis = normalizeAmount(tx.Vout[i].Value, tx.Vout[i].ValueSatoshis)
+	}
+
+	if _, ok := s.transactions[tx.TxID]; !ok {
+		s.txOrder = append(s.txOrder, tx.TxID)
+	}
+	s.transactions[tx.TxID] = &tx
+}
+
+// AddBlock adds a block (and optional raw block hex) to the chain state
+//
+// The highest block is the chain tip (best block hash) and is used for confirmations
+func (s *Server) AddBlock(block bitindex.BlockResponse, rawBlock string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.blocks[block.Hash] = &block
+	s.blockHashes[block.Height] = block.Hash
+	if len(rawBlock) > 0 {
+		s.rawBlocks[block.Hash] = rawBlock
+	}
+	if len(s.tipHash) == 0 || block.Height >= s.blocks[s.tipHash].Height {
+		s.tipHash = block.Hash
+	}
+}
+
+// SetChainInfo sets the chain info returned by the status endpoints
+func (s *Server) SetChainInfo(info bitindex.ChainInfoResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chainInfo = info
+}
+
+// AddXpub adds an xpub with its derived addresses (chain 0 is receive, chain 1 is change)
+func (s *Server) AddXpub(xPub string, addresses ...bitindex.XPubAddress) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.xpubs[xPub]
+	if !ok {
+		state = &xpubState{reserved: make(map[string]time.Time)}
+		s.xpubs[xPub] = state
+	}
+	state.addresses = append(state.addresses, addresses...)
+	sort.SliceStable(state.addresses, func(i, j int) bool {
+		if state.addresses[i].Chain != state.addresses[j].Chain {
+			return state.addresses[i].Chain < state.addresses[j].Chain
+		}
+		return state.addresses[i].Num < state.addresses[j].Num
+	})
+}
+
+// SetWebhookConfig sets the webhook config
+func (s *Server) SetWebhookConfig(config bitindex.WebhookConfigResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.webhookConfig = config
+}
+
+// MonitoredAddresses returns the monitored addresses
+func (s *Server) MonitoredAddresses() bitindex.MonitoredAddresses {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append(bitindex.MonitoredAddresses(nil), s.monitoredAddresses...)
+}
+
+// Broadcasts returns the raw transactions received by tx/send (in order)
+func (s *Server) Broadcasts() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]string(nil), s.broadcasts...)
+}
+
+// normalizeAmount will fill in the BSV or satoshi amount from the other
+func normalizeAmount(value, satoshis bitindex.Satoshis) (bitindex.Satoshis, bitindex.Satoshis) {
+	if satoshis == 0 && value != 0 {
+		satoshis = value
+	} else if value == 0 && satoshis != 0 {
+		value = satoshis
+	}
+	return value, satoshis
+}
+
+// tipHeight returns the height of the chain tip (lock must be held)
+func (s *Server) tipHeight() int64 {
+	if block, ok := s.blocks[s.tipHash]; ok {
+		return block.Height
+	}
+	return 0
+}
+
+// confirmations returns the confirmations for the transaction (lock must be held)
+func (s *Server) confirmations(tx *bitindex.Transaction) int64 {
+	if tx.BlockHeight > 0 {
+		if tip := s.tipHeight(); tip >= tx.BlockHeight {
+			return tip - tx.BlockHeight + 1
+		}
+	}
+	return tx.Confirmations
+}
+
+// transaction returns a copy of the transaction with derived fields (lock must be held)
+func (s *Server) transaction(txID string) (*bitindex.Transaction, bool) {
+	tx, ok := s.transactions[txID]
+	if !ok {
+		return nil, false
+	}
+	result := *tx
+	result.Confirmations = s.confirmations(tx)
+
+	// Mark the spent outputs
+	result.Vout = append(result.Vout[:0:0], tx.Vout...)
+	spent := s.spentOutputs()
+	for i := range result.Vout {
+		if spender, isSpent := spent[outpoint(txID, result.Vout[i].N)]; isSpent {
+			result.Vout[i].SpentTxID = spender.TxID
+			result.Vout[i].SpentHeight = spender.BlockHeight
+		}
+	}
+	return &result, true
+}
+
+// involvesAddress returns true if the transaction spends from or pays to the address
+func involvesAddress(tx *bitindex.Transaction, address string) bool {
+	return containsString(tx.Addresses(), address)
+}
+
+// addressTransactions returns the transactions for the addresses, newest first (lock must be held)
+func (s *Server) addressTransactions(addresses []string) (transactions []*bitindex.Transaction) {
+	for _, txID := range s.txOrder {
+		tx := s.transactions[txID]
+		for _, address := range addresses {
+			if involvesAddress(tx, address) {
+				result, _ := s.transaction(txID)
+				transactions = append(transactions, result)
+				break
+			}
+		}
+	}
+
+	// Unconfirmed first, then by height descending
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return sortHeight(transactions[i].BlockHeight) > sortHeight(transactions[j].BlockHeight)
+	})
+	return
+}
+
+// sortHeight puts unconfirmed transactions (height 0) at the top
+func sortHeight(height int64) int64 {
+	if height <= 0 {
+		return math.MaxInt64
+	}
+	return height
+}
+
+// outpoint returns the txid:vout key
+func outpoint(txID string, vout int) string {
+	return txID + ":" + strconv.Itoa(vout)
+}
+
+// spentOutputs returns the spending transaction by outpoint (lock must be held)
+func (s *Server) spentOutputs() map[string]*bitindex.Transaction {
+	spent := make(map[string]*bitindex.Transaction)
+	for _, tx := range s.transactions {
+		for _, vin := range tx.Vin {
+			if len(vin.TxID) > 0 {
+				spent[outpoint(vin.TxID, vin.Vout)] = tx
+			}
+		}
+	}
+	return spent
+}
+
+// unspentTransactions returns the UTXOs for the addresses (lock must be held)
+func (s *Server) unspentTransactions(addresses []string) (utxos bitindex.UnspentTransactions) {
+	spent := s.spentOutputs()
+	for _, txID := range s.txOrder {
+		tx := s.transactions[txID]
+		for _, vout := range tx.Vout {
+			if _, isSpent := spent[outpoint(txID, vout.N)]; isSpent {
+				continue
+			}
+			for _, voutAddress := range vout.ScriptPubKey.Addresses {
+				if !containsString(addresses, voutAddress) {
+					continue
+				}
+				utxos = append(utxos, bitindex.UnspentTransaction{
+					Address:       voutAddress,
+					Amount:        vout.Value,
+					Confirmations: s.confirmations(tx),
+					Height:        tx.BlockHeight,
+					OutputIndex:   int64(vout.N),
+					Satoshis:      vout.ValueSatoshis,
+					Script:        vout.ScriptPubKey.Hex,
+					ScriptPubKey:  vout.ScriptPubKey.Hex,
+					TxID:          txID,
+					Value:         vout.ValueSatoshis,
+					Vout:          vout.N,
+				})
+			}
+		}
+	}
+	return
+}
+
+// addressInfo returns the derived address info (lock must be held)
+func (s *Server) addressInfo(address string) *bitindex.AddressInfo {
+	info := &bitindex.AddressInfo{Address: address, Transactions: []string{}}
+
+	// Balances from the UTXOs
+	for _, utxo := range s.unspentTransactions([]string{address}) {
+		if utxo.Height > 0 {
+			info.BalanceSatoshis += utxo.Satoshis
+		} else {
+			info.UnconfirmedBalanceSatoshis += utxo.Satoshis
+		}
+	}
+
+	// Totals & history from the transactions
+	for _, tx := range s.addressTransactions([]string{address}) {
+		info.Transactions = append(info.Transactions, tx.TxID)
+		if tx.BlockHeight > 0 {
+			info.TxAppearances++
+		} else {
+			info.UnconfirmedTxAppearances++
+		}
+		info.TotalSentSatoshis += tx.AmountFrom(address)
+		info.TotalReceivedSatoshis += tx.AmountTo(address)
+	}
+
+	info.Balance = info.BalanceSatoshis
+	info.TotalReceived = info.TotalReceivedSatoshis
+	info.TotalSent = info.TotalSentSatoshis
+	info.UnconfirmedBalance = info.UnconfirmedBalanceSatoshis
+	return info
+}
+
+// xpubAddressList returns the addresses of the xpub (lock must be held)
+func (s *Server) xpubAddressList(state *xpubState) (addresses []string) {
+	for _, address := range state.addresses {
+		addresses = append(addresses, address.Address)
+	}
+	return
+}
+
+// xpubAddress returns the xpub address details for the address (lock must be held)
+func xpubAddress(state *xpubState, address string) (bitindex.XPubAddress, bool) {
+	for _, xpubAddress := range state.addresses {
+		if xpubAddress.Address == address {
+			return xpubAddress, true
+		}
+	}
+	return bitindex.XPubAddress{}, false
+}
+
+// containsString returns true if the value is in the list
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package bitindextest
+
+import "github.com/mrz1836/go-bitindex"
+
+// Input is a transaction input that spends a previous output
+type Input struct {
+	Address  string // is the address of the output being spent
+	Satoshis int64  // is the value of the output being spent
+	TxID     string // is the txid of the output being spent
+	Vout     int    // is the index of the output being spent
+}
+
+// Output is a transaction output that pays the address
+type Output struct {
+	Address  string // is the address paid
+	Satoshis int64  // is the value of the output
+	Script   string // is the locking script hex (optional)
+}
+
+// NewTransaction builds a transaction for AddTransaction() (a height of 0 is unconfirmed)
+func NewTransaction(txID string, blockHeight int64, inputs []Input, outputs []Output) bitindex.Transaction {
+	tx := bitindex.Transaction{
+		BlockHeight: blockHeight,
+		Hash:        txID,
+		TxID:        txID,
+		Vin:         []bitindex.TransactionInput{},
+		Vout:        []bitindex.TransactionOutput{},
+	}
+
+	for i, input := range inputs {
+		tx.Vin = append(tx.Vin, bitindex.TransactionInput{
+			Address:       input.Address,
+			AddressAddr:   input.Address,
+			N:             i,
+			TxID:          input.TxID,
+			Value:         bitindex.Satoshis(input.Satoshis),
+			ValueSatoshis: bitindex.Satoshis(input.Satoshis),
+			Vout:          input.Vout,
+		})
+		tx.ValueIn += bitindex.Satoshis(input.Satoshis)
+	}
+	for i, output := range outputs {
+		scriptPubKey := bitindex.ScriptPubKey{Hex: output.Script, Type: "pubkeyhash"}
+		if len(output.Address) > 0 {
+			scriptPubKey.Addresses = []string{output.Address}
+		}
+		tx.Vout = append(tx.Vout, bitindex.TransactionOutput{
+			N:             i,
+			ScriptPubKey:  scriptPubKey,
+			Value:         bitindex.Satoshis(output.Satoshis),
+			ValueSatoshis: bitindex.Satoshis(output.Satoshis),
+		})
+		tx.ValueOut += bitindex.Satoshis(output.Satoshis)
+	}
+
+	if len(inputs) > 0 {
+		tx.Fees = tx.ValueIn - tx.ValueOut
+	}
+	return tx
+}
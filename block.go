@@ -1,8 +1,11 @@
 package bitindex
 
 import (
+	"context"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 )
 
@@ -10,27 +13,38 @@ import (
 //
 // For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Block
 func (c *Client) GetBlockHashByHeight(height int64) (blockHash *BlockHashByHeightResponse, err error) {
+	return c.GetBlockHashByHeightWithContext(context.Background(), height)
+}
+
+// GetBlockHashByHeightWithContext is the same as GetBlockHashByHeight() but will be canceled when the given context is done
+//
+// For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Block
+func (c *Client) GetBlockHashByHeightWithContext(ctx context.Context, height int64) (blockHash *BlockHashByHeightResponse, err error) {
 
 	// Create the request
-	var resp string
+	var resp *requestResponse
 	// /api/v3/network/block-index/height
-	resp, err = c.Request(fmt.Sprintf("block-index/%d", height), http.MethodGet, nil)
+	resp, err = c.request(ctx, &apiRequest{
+		Endpoint:   fmt.Sprintf("block-index/%d", height),
+		Idempotent: true,
+		Method:     http.MethodGet,
+		Name:       "GetBlockHashByHeight",
+	})
 	if err != nil {
 		return
 	}
 
-	// Process the response
-	blockHash = new(BlockHashByHeightResponse)
-	if err = json.Unmarshal([]byte(resp), blockHash); err != nil {
+	// Error from request?
+	if resp.StatusCode != http.StatusOK {
+		err = newAPIError(resp)
 		return
 	}
 
-	// Error from request?
-	if c.LastRequest.StatusCode != http.StatusOK {
-		err = fmt.Errorf("error: %s", blockHash.ErrorMessage)
+	// Process the response
+	blockHash = new(BlockHashByHeightResponse)
+	if err = json.Unmarshal(resp.Body, blockHash); err != nil {
 		return
 	}
-
 	return
 }
 
@@ -38,24 +52,36 @@ func (c *Client) GetBlockHashByHeight(height int64) (blockHash *BlockHashByHeigh
 //
 // For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Block
 func (c *Client) GetBlockHeader(hash string) (blockHeader *BlockHeaderResponse, err error) {
+	return c.GetBlockHeaderWithContext(context.Background(), hash)
+}
+
+// GetBlockHeaderWithContext is the same as GetBlockHeader() but will be canceled when the given context is done
+//
+// For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Block
+func (c *Client) GetBlockHeaderWithContext(ctx context.Context, hash string) (blockHeader *BlockHeaderResponse, err error) {
 
 	// Create the request
-	var resp string
+	var resp *requestResponse
 	// /api/v3/network/blockheader/hash
-	resp, err = c.Request(fmt.Sprintf("blockheader/%s", hash), http.MethodGet, nil)
+	resp, err = c.request(ctx, &apiRequest{
+		Endpoint:   fmt.Sprintf("blockheader/%s", hash),
+		Idempotent: true,
+		Method:     http.MethodGet,
+		Name:       "GetBlockHeader",
+	})
 	if err != nil {
 		return
 	}
 
-	// Process the response
-	blockHeader = new(BlockHeaderResponse)
-	if err = json.Unmarshal([]byte(resp), blockHeader); err != nil {
+	// Error from request?
+	if resp.StatusCode != http.StatusOK {
+		err = newAPIError(resp)
 		return
 	}
 
-	// Error from request?
-	if c.LastRequest.StatusCode != http.StatusOK {
-		err = fmt.Errorf("error: %s", blockHeader.ErrorMessage)
+	// Process the response
+	blockHeader = new(BlockHeaderResponse)
+	if err = json.Unmarshal(resp.Body, blockHeader); err != nil {
 		return
 	}
 	return
@@ -65,24 +91,36 @@ func (c *Client) GetBlockHeader(hash string) (blockHeader *BlockHeaderResponse,
 //
 // For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Block
 func (c *Client) GetBlock(hash string) (block *BlockResponse, err error) {
+	return c.GetBlockWithContext(context.Background(), hash)
+}
+
+// GetBlockWithContext is the same as GetBlock() but will be canceled when the given context is done
+//
+// For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Block
+func (c *Client) GetBlockWithContext(ctx context.Context, hash string) (block *BlockResponse, err error) {
 
 	// Create the request
-	var resp string
+	var resp *requestResponse
 	// /api/v3/network/block/hash
-	resp, err = c.Request(fmt.Sprintf("block/%s", hash), http.MethodGet, nil)
+	resp, err = c.request(ctx, &apiRequest{
+		Endpoint:   fmt.Sprintf("block/%s", hash),
+		Idempotent: true,
+		Method:     http.MethodGet,
+		Name:       "GetBlock",
+	})
 	if err != nil {
 		return
 	}
 
-	// Process the response
-	block = new(BlockResponse)
-	if err = json.Unmarshal([]byte(resp), block); err != nil {
+	// Error from request?
+	if resp.StatusCode != http.StatusOK {
+		err = newAPIError(resp)
 		return
 	}
 
-	// Error from request?
-	if c.LastRequest.StatusCode != http.StatusOK {
-		err = fmt.Errorf("error: %s", block.ErrorMessage)
+	// Process the response
+	block = new(BlockResponse)
+	if err = json.Unmarshal(resp.Body, block); err != nil {
 		return
 	}
 	return
@@ -92,25 +130,85 @@ func (c *Client) GetBlock(hash string) (block *BlockResponse, err error) {
 //
 // For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Block
 func (c *Client) GetBlockRaw(hash string) (rawBlock *BlockRawResponse, err error) {
+	return c.GetBlockRawWithContext(context.Background(), hash)
+}
+
+// GetBlockRawWithContext is the same as GetBlockRaw() but will be canceled when the given context is done
+//
+// For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Block
+func (c *Client) GetBlockRawWithContext(ctx context.Context, hash string) (rawBlock *BlockRawResponse, err error) {
 
 	// Create the request
-	var resp string
+	var resp *requestResponse
 	// /api/v3/network/rawblock/hash
-	resp, err = c.Request(fmt.Sprintf("rawblock/%s", hash), http.MethodGet, nil)
+	resp, err = c.request(ctx, &apiRequest{
+		Endpoint:   fmt.Sprintf("rawblock/%s", hash),
+		Idempotent: true,
+		Method:     http.MethodGet,
+		Name:       "GetBlockRaw",
+	})
 	if err != nil {
 		return
 	}
 
+	// Error from request?
+	if resp.StatusCode != http.StatusOK {
+		err = newAPIError(resp)
+		return
+	}
+
 	// Process the response
 	rawBlock = new(BlockRawResponse)
-	if err = json.Unmarshal([]byte(resp), rawBlock); err != nil {
+	if err = json.Unmarshal(resp.Body, rawBlock); err != nil {
+		return
+	}
+	return
+}
+
+// GetBlockRawReader this endpoint streams the raw block by hash (close the reader when done).
+//
+// The "rawblock" field is decoded from the response body as the transactions are read, so the
+// block is never held in memory (see GetBlockRaw() for the whole response)
+//
+// For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Block
+func (c *Client) GetBlockRawReader(hash string) (reader *BlockReader, err error) {
+	return c.GetBlockRawReaderWithContext(context.Background(), hash)
+}
+
+// GetBlockRawReaderWithContext is the same as GetBlockRawReader() but will be canceled when the given context is done
+//
+// For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Block
+func (c *Client) GetBlockRawReaderWithContext(ctx context.Context, hash string) (reader *BlockReader, err error) {
+
+	// Create the request
+	var resp *requestResponse
+	// /api/v3/network/rawblock/hash
+	resp, err = c.request(ctx, &apiRequest{
+		Endpoint:   fmt.Sprintf("rawblock/%s", hash),
+		Idempotent: true,
+		Method:     http.MethodGet,
+		Name:       "GetBlockRaw",
+		Stream:     true,
+	})
+	if err != nil {
 		return
 	}
 
 	// Error from request?
-	if c.LastRequest.StatusCode != http.StatusOK {
-		err = fmt.Errorf("error: %s", rawBlock.ErrorMessage)
+	if resp.StatusCode != http.StatusOK {
+		err = newAPIError(resp)
 		return
 	}
+
+	// Stream the hex of the rawblock field into the block reader
+	var rawBlock io.Reader
+	if rawBlock, err = rawBlockField(resp.Stream); err == nil {
+		reader, err = NewBlockReader(hex.NewDecoder(rawBlock))
+	}
+	if err != nil {
+		_ = resp.Stream.Close()
+		return nil, err
+	}
+	reader.closer = resp.Stream
 	return
 }
@@ -0,0 +1,122 @@
+package bitindex
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidBlockHeader is returned when a block header field can not be parsed
+	ErrInvalidBlockHeader = errors.New("invalid block header")
+
+	// ErrBlockHashMismatch is returned when the hash of the header is not the block hash
+	ErrBlockHashMismatch = errors.New("block hash mismatch")
+
+	// ErrInvalidProofOfWork is returned when the block hash is above the target of the header
+	ErrInvalidProofOfWork = errors.New("invalid proof of work")
+)
+
+// maxTarget is the largest possible target (2^256 - 1)
+var maxTarget = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// Header will parse the header fields of the response (Hash is computed from the fields)
+//
+// Compare the computed hash to the response with Verify()
+func (b *BlockHeaderResponse) Header() (*RawBlockHeader, error) {
+	bits, err := strconv.ParseUint(b.Bits, 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bits %q", ErrInvalidBlockHeader, b.Bits)
+	} else if b.Nonce < 0 || b.Nonce > 0xffffffff || b.Time < 0 || b.Time > 0xffffffff {
+		return nil, fmt.Errorf("%w: nonce %d or time %d out of range", ErrInvalidBlockHeader, b.Nonce, b.Time)
+	}
+
+	header := &RawBlockHeader{
+		Bits:              uint32(bits),
+		MerkleRoot:        strings.ToLower(b.MerkleRoot),
+		Nonce:             uint32(b.Nonce),
+		PreviousBlockHash: strings.ToLower(b.PreviousBlockHash),
+		Time:              uint32(b.Time),
+		Version:           int32(b.Version),
+	}
+
+	// The genesis block has no previous block
+	if len(header.PreviousBlockHash) == 0 {
+		header.PreviousBlockHash = coinbaseTxID
+	}
+	for _, value := range []string{header.MerkleRoot, header.PreviousBlockHash} {
+		if decoded, err := hex.DecodeString(value); err != nil || len(decoded) != 32 {
+			return nil, fmt.Errorf("%w: hash %q", ErrInvalidBlockHeader, value)
+		}
+	}
+
+	header.Hash = header.BlockHash()
+	return header, nil
+}
+
+// Verify will check the header fields hash to the block hash and the hash meets the target in bits
+func (b *BlockHeaderResponse) Verify() error {
+	header, err := b.Header()
+	if err != nil {
+		return err
+	} else if !strings.EqualFold(header.Hash, b.Hash) {
+		return fmt.Errorf("%w: the header hashes to %s not %s", ErrBlockHashMismatch, header.Hash, b.Hash)
+	}
+	return header.CheckProofOfWork()
+}
+
+// Bytes returns the 80 byte serialized header (invalid hash fields are zeros)
+func (h *RawBlockHeader) Bytes() []byte {
+	header := make([]byte, blockHeaderSize)
+	binary.LittleEndian.PutUint32(header[0:4], uint32(h.Version))
+	previous, _ := hex.DecodeString(h.PreviousBlockHash)
+	copy(header[4:36], reverseBytes(previous))
+	merkleRoot, _ := hex.DecodeString(h.MerkleRoot)
+	copy(header[36:68], reverseBytes(merkleRoot))
+	binary.LittleEndian.PutUint32(header[68:72], h.Time)
+	binary.LittleEndian.PutUint32(header[72:76], h.Bits)
+	binary.LittleEndian.PutUint32(header[76:80], h.Nonce)
+	return header
+}
+
+// BlockHash returns the hash of the serialized header (hex, displayed order)
+func (h *RawBlockHeader) BlockHash() string {
+	return hex.EncodeToString(reverseBytes(doubleSha256(h.Bytes())))
+}
+
+// Target returns the target encoded in bits (nil if the encoding is negative or overflows)
+func (h *RawBlockHeader) Target() *big.Int {
+	mantissa := int64(h.Bits & 0x007fffff)
+	exponent := uint(h.Bits >> 24)
+	if h.Bits&0x00800000 != 0 && mantissa != 0 {
+		return nil
+	}
+
+	target := big.NewInt(mantissa)
+	if exponent <= 3 {
+		target.Rsh(target, 8*(3-exponent))
+	} else {
+		target.Lsh(target, 8*(exponent-3))
+	}
+	if target.Cmp(maxTarget) > 0 {
+		return nil
+	}
+	return target
+}
+
+// CheckProofOfWork will return ErrInvalidProofOfWork if the header hash is above the target
+func (h *RawBlockHeader) CheckProofOfWork() error {
+	target := h.Target()
+	if target == nil || target.Sign() == 0 {
+		return fmt.Errorf("%w: bits %08x is not a valid target", ErrInvalidProofOfWork, h.Bits)
+	}
+	hash, _ := new(big.Int).SetString(h.BlockHash(), 16)
+	if hash.Cmp(target) > 0 {
+		return fmt.Errorf("%w: hash %s is above the target %064x", ErrInvalidProofOfWork, h.BlockHash(), target)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package bitindex
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// testGenesisHeader returns the genesis block header as the api returns it
+func testGenesisHeader() *BlockHeaderResponse {
+	return &BlockHeaderResponse{
+		Bits:       "1d00ffff",
+		Hash:       testGenesisBlockHash,
+		MerkleRoot: testGenesisTxID,
+		Nonce:      2083236893,
+		Time:       1231006505,
+		Version:    1,
+	}
+}
+
+// TestBlockHeaderResponse_Verify tests verifying the genesis header
+func TestBlockHeaderResponse_Verify(t *testing.T) {
+	t.Parallel()
+
+	response := testGenesisHeader()
+	if err := response.Verify(); err != nil {
+		t.Fatal(err)
+	}
+
+	header, err := response.Header()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(header.Bytes()) != testGenesisBlock[:2*blockHeaderSize] || header.Hash != testGenesisBlockHash {
+		t.Fatalf("unexpected header: %x", header.Bytes())
+	}
+
+	// Same as the decoded raw block
+	block, err := DecodeRawBlock(testGenesisBlock)
+	if err != nil {
+		t.Fatal(err)
+	} else if block.Header != *header {
+		t.Fatalf("expected the decoded header %+v got: %+v", block.Header, *header)
+	}
+
+	// A different field does not hash to the block hash
+	response.Nonce++
+	if err = response.Verify(); !errors.Is(err, ErrBlockHashMismatch) {
+		t.Fatalf("expected ErrBlockHashMismatch got: %v", err)
+	}
+}
+
+// TestRawBlockHeader_CheckProofOfWork tests the target and proof of work
+func TestRawBlockHeader_CheckProofOfWork(t *testing.T) {
+	t.Parallel()
+
+	header, err := testGenesisHeader().Header()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target := header.Target(); target.Text(16) != "ffff0000000000000000000000000000000000000000000000000000" {
+		t.Fatalf("unexpected target: %x", target)
+	}
+
+	// Hash above the target
+	header.Nonce++
+	if err = header.CheckProofOfWork(); !errors.Is(err, ErrInvalidProofOfWork) {
+		t.Fatalf("expected ErrInvalidProofOfWork got: %v", err)
+	}
+
+	// Targets
+	var tests = []struct {
+		bits   uint32
+		target string
+	}{
+		{0x207fffff, "7fffff0000000000000000000000000000000000000000000000000000000000"},
+		{0x03123456, "123456"},
+		{0x02123456, "1234"},
+		{0x01003456, "0"},
+		{0x04923456, ""}, // negative
+		{0x23000001, ""}, // overflow
+	}
+	for _, test := range tests {
+		header.Bits = test.bits
+		target := header.Target()
+		if test.target == "" && target != nil {
+			t.Fatalf("%08x: expected no target got: %x", test.bits, target)
+		} else if test.target != "" && (target == nil || target.Text(16) != test.target) {
+			t.Fatalf("%08x: expected %s got: %x", test.bits, test.target, target)
+		}
+	}
+	header.Bits = 0x04923456
+	if err = header.CheckProofOfWork(); !errors.Is(err, ErrInvalidProofOfWork) {
+		t.Fatalf("expected ErrInvalidProofOfWork got: %v", err)
+	}
+}
+
+// TestBlockHeaderResponse_Header_Errors tests invalid header fields
+func TestBlockHeaderResponse_Header_Errors(t *testing.T) {
+	t.Parallel()
+
+	var tests = []func(response *BlockHeaderResponse){
+		func(response *BlockHeaderResponse) { response.Bits = "xyz" },
+		func(response *BlockHeaderResponse) { response.Bits = "1d00ffff00" },
+		func(response *BlockHeaderResponse) { response.Nonce = -1 },
+		func(response *BlockHeaderResponse) { response.Time = 1 << 32 },
+		func(response *BlockHeaderResponse) { response.MerkleRoot = "abc" },
+		func(response *BlockHeaderResponse) { response.PreviousBlockHash = "zz" },
+	}
+	for i, change := range tests {
+		response := testGenesisHeader()
+		change(response)
+		if err := response.Verify(); !errors.Is(err, ErrInvalidBlockHeader) {
+			t.Fatalf("%d: expected ErrInvalidBlockHeader got: %v", i, err)
+		}
+	}
+}
@@ -4,13 +4,8 @@ import "testing"
 
 // TestClient_GetBlockHashByHeight tests the GetBlockHashByHeight()
 func TestClient_GetBlockHashByHeight(t *testing.T) {
-	// Skip this test in short mode (not needed)
-	if testing.Short() {
-		t.Skip("skipping testing in short mode")
-	}
-
-	// Create a new client object to handle your queries (supply an API Key)
-	client, err := NewClient(testAPIKey, NetworkMain, nil)
+	// Create a new client object to handle your queries (replays the cassette if recorded)
+	client, err := newCassetteClient(t, NetworkMain)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -29,13 +24,8 @@ func TestClient_GetBlockHashByHeight(t *testing.T) {
 
 // TestClient_GetBlockHeader tests the GetBlockHeader()
 func TestClient_GetBlockHeader(t *testing.T) {
-	// Skip this test in short mode (not needed)
-	if testing.Short() {
-		t.Skip("skipping testing in short mode")
-	}
-
-	// Create a new client object to handle your queries (supply an API Key)
-	client, err := NewClient(testAPIKey, NetworkMain, nil)
+	// Create a new client object to handle your queries (replays the cassette if recorded)
+	client, err := newCassetteClient(t, NetworkMain)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -54,13 +44,8 @@ func TestClient_GetBlockHeader(t *testing.T) {
 
 // TestClient_GetBlock tests the GetBlock()
 func TestClient_GetBlock(t *testing.T) {
-	// Skip this test in short mode (not needed)
-	if testing.Short() {
-		t.Skip("skipping testing in short mode")
-	}
-
-	// Create a new client object to handle your queries (supply an API Key)
-	client, err := NewClient(testAPIKey, NetworkMain, nil)
+	// Create a new client object to handle your queries (replays the cassette if recorded)
+	client, err := newCassetteClient(t, NetworkMain)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -83,13 +68,8 @@ func TestClient_GetBlock(t *testing.T) {
 
 // TestClient_GetBlockRaw tests the GetBlockRaw()
 func TestClient_GetBlockRaw(t *testing.T) {
-	// Skip this test in short mode (not needed)
-	if testing.Short() {
-		t.Skip("skipping testing in short mode")
-	}
-
-	// Create a new client object to handle your queries (supply an API Key)
-	client, err := NewClient(testAPIKey, NetworkMain, nil)
+	// Create a new client object to handle your queries (replays the cassette if recorded)
+	client, err := newCassetteClient(t, NetworkMain)
 	if err != nil {
 		t.Fatal(err)
 	}
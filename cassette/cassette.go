@@ -0,0 +1,333 @@
+/*
+Package cassette records and replays HTTP interactions (request/response pairs) as fixture files
+
+A Recorder is an http.RoundTripper that can be plugged into any http.Client, including the
+bitindex.Client via Options.Transport:
+
+	recorder, err := cassette.New("testdata/cassettes/address_info.json", cassette.ModeReplay, nil)
+	options := bitindex.ClientDefaultOptions()
+	options.Transport = recorder
+	client, err := bitindex.NewClient("key", bitindex.NetworkMain, options)
+
+In ModeRecord the requests are sent with the next transport and saved (call Save() when done),
+the api_key header (and any other secret header) is never written to the fixture. In ModeReplay
+the responses are served from the fixture and unmatched requests fail with ErrNoMatch.
+*/
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Mode is the mode of the recorder
+type Mode int
+
+const (
+	// ModeReplay serves the responses from the fixture (no network)
+	ModeReplay Mode = iota
+
+	// ModeRecord sends the requests and records the interactions
+	ModeRecord
+)
+
+// redacted is the value for scrubbed query parameters
+const redacted = "REDACTED"
+
+// ErrNoMatch is returned in replay mode when no recorded interaction matches the request
+var ErrNoMatch = errors.New("cassette: no recorded interaction matches the request")
+
+// SecretHeaders are the headers that are never recorded
+var SecretHeaders = []string{"api_key", "Authorization", "Cookie", "Proxy-Authorization"}
+
+// Cassette is the fixture file
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request/response pair
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is the recorded request (secrets scrubbed)
+type Request struct {
+	Body    string      `json:"body,omitempty"`
+	Headers http.Header `json:"headers,omitempty"`
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+}
+
+// Response is the recorded response
+type Response struct {
+	Body       string      `json:"body"`
+	Headers    http.Header `json:"headers,omitempty"`
+	StatusCode int         `json:"status_code"`
+}
+
+// Recorder is an http.RoundTripper that records or replays the interactions
+type Recorder struct {
+	cassette *Cassette         // is the loaded (or recorded) cassette
+	mode     Mode              // is the recorder mode
+	mu       sync.Mutex        // guards the cassette
+	next     http.RoundTripper // is the real transport (record mode)
+	path     string            // is the fixture file path
+	used     []bool            // are the replayed interactions
+}
+
+// New will create a recorder for the fixture file
+//
+// In ModeReplay the fixture must exist. In ModeRecord a nil next transport uses http.DefaultTransport
+func New(path string, mode Mode, next http.RoundTripper) (*Recorder, error) {
+	r := &Recorder{cassette: &Cassette{}, mode: mode, next: next, path: path}
+
+	switch mode {
+	case ModeReplay:
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(data, r.cassette); err != nil {
+			return nil, fmt.Errorf("cassette: invalid fixture %s: %w", path, err)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	case ModeRecord:
+		if r.next == nil {
+			r.next = http.DefaultTransport
+		}
+	default:
+		return nil, fmt.Errorf("cassette: unknown mode %d", mode)
+	}
+	return r, nil
+}
+
+// Exists returns true if the fixture file exists
+func Exists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// Mode returns the mode of the recorder
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Interactions returns the number of recorded (or loaded) interactions
+func (r *Recorder) Interactions() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.cassette.Interactions)
+}
+
+// RoundTrip records or replays the request
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	// Read the body (and restore it for the next transport)
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		_ = req.Body.Close()
+	}
+	recorded := newRequest(req, body)
+
+	if r.mode == ModeReplay {
+		return r.replay(req, recorded)
+	}
+
+	// Send the request
+	outgoing := req.Clone(req.Context())
+	outgoing.Body = ioutil.NopCloser(bytes.NewReader(body))
+	resp, err := r.next.RoundTrip(outgoing)
+	if err != nil {
+		return nil, err
+	}
+
+	// Record the response
+	var responseBody []byte
+	responseBody, err = ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(responseBody))
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, &Interaction{
+		Request: recorded,
+		Response: Response{
+			Body:       string(responseBody),
+			Headers:    scrubHeaders(resp.Header),
+			StatusCode: resp.StatusCode,
+		},
+	})
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+// replay serves the first unused interaction that matches the request
+func (r *Recorder) replay(req *http.Request, recorded Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] || !matches(&interaction.Request, &recorded) {
+			continue
+		}
+		r.used[i] = true
+
+		headers := interaction.Response.Headers.Clone()
+		if headers == nil {
+			headers = http.Header{}
+		}
+		return &http.Response{
+			Body:          ioutil.NopCloser(strings.NewReader(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Header:        headers,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Request:       req,
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrNoMatch, recorded.Method, recorded.URL)
+}
+
+// Save writes the recorded interactions to the fixture file (record mode only)
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(r.path), 0750); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.path, append(data, '\n'), 0600)
+}
+
+// Unused returns the interactions that were not replayed (replay mode only)
+func (r *Recorder) Unused() (unused []*Interaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.cassette.Interactions {
+		if i < len(r.used) && !r.used[i] {
+			unused = append(unused, interaction)
+		}
+	}
+	return
+}
+
+// newRequest will create the recorded request with the secrets scrubbed
+func newRequest(req *http.Request, body []byte) Request {
+	return Request{
+		Body:    string(body),
+		Headers: scrubHeaders(req.Header),
+		Method:  req.Method,
+		URL:     scrubURL(req.URL),
+	}
+}
+
+// scrubHeaders returns a copy of the headers without the secret headers
+func scrubHeaders(headers http.Header) http.Header {
+	if len(headers) == 0 {
+		return nil
+	}
+	scrubbed := headers.Clone()
+	for _, header := range SecretHeaders {
+		scrubbed.Del(header)
+		delete(scrubbed, header) // non-canonical keys (IE: api_key)
+	}
+	return scrubbed
+}
+
+// scrubURL returns the url with the secret query parameters redacted (sorted for matching)
+func scrubURL(u *url.URL) string {
+	scrubbed := *u
+	scrubbed.User = nil
+	query := scrubbed.Query()
+	for key := range query {
+		for _, header := range SecretHeaders {
+			if strings.EqualFold(key, header) {
+				query.Set(key, redacted)
+			}
+		}
+	}
+	scrubbed.RawQuery = query.Encode()
+	return scrubbed.String()
+}
+
+// matches returns true if the method, path, query and body match (the host is ignored)
+func matches(recorded, req *Request) bool {
+	if recorded.Method != req.Method || !sameBody(recorded.Body, req.Body) {
+		return false
+	}
+	recordedURL, err := url.Parse(recorded.URL)
+	if err != nil {
+		return false
+	}
+	var requestURL *url.URL
+	if requestURL, err = url.Parse(req.URL); err != nil {
+		return false
+	}
+	return recordedURL.Path == requestURL.Path && sameQuery(recordedURL.Query(), requestURL.Query())
+}
+
+// sameQuery compares the query parameters (order does not matter)
+func sameQuery(a, b url.Values) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, values := range a {
+		other := b[key]
+		if len(values) != len(other) {
+			return false
+		}
+		values, other = append([]string(nil), values...), append([]string(nil), other...)
+		sort.Strings(values)
+		sort.Strings(other)
+		for i := range values {
+			if values[i] != other[i] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// sameBody compares the bodies (JSON bodies are compared by value)
+func sameBody(a, b string) bool {
+	if a == b {
+		return true
+	}
+	var jsonA, jsonB interface{}
+	if json.Unmarshal([]byte(a), &jsonA) != nil || json.Unmarshal([]byte(b), &jsonB) != nil {
+		return false
+	}
+	normalizedA, _ := json.Marshal(jsonA)
+	normalizedB, _ := json.Marshal(jsonB)
+	return bytes.Equal(normalizedA, normalizedB)
+}
@@ -1,6 +1,7 @@
 package bitindex
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 )
@@ -9,18 +10,36 @@ import (
 //
 // For more information: https://www.bitindex.network/developers/api-documentation-v3.html#ChainInfo
 func (c *Client) ChainInfo() (chainInfo *ChainInfoResponse, err error) {
+	return c.ChainInfoWithContext(context.Background())
+}
+
+// ChainInfoWithContext is the same as ChainInfo() but will be canceled when the given context is done
+//
+// For more information: https://www.bitindex.network/developers/api-documentation-v3.html#ChainInfo
+func (c *Client) ChainInfoWithContext(ctx context.Context) (chainInfo *ChainInfoResponse, err error) {
 
 	// Create the request
-	var resp string
+	var resp *requestResponse
 	// /api/v3/network/status
-	resp, err = c.Request("status?q=chainInfo", http.MethodGet, nil)
+	resp, err = c.request(ctx, &apiRequest{
+		Endpoint:   "status?q=chainInfo",
+		Idempotent: true,
+		Method:     http.MethodGet,
+		Name:       "ChainInfo",
+	})
 	if err != nil {
 		return
 	}
 
+	// Error from request?
+	if resp.StatusCode != http.StatusOK {
+		err = newAPIError(resp)
+		return
+	}
+
 	// Process the response
 	chainInfo = new(ChainInfoResponse)
-	if err = json.Unmarshal([]byte(resp), chainInfo); err != nil {
+	if err = json.Unmarshal(resp.Body, chainInfo); err != nil {
 		return
 	}
 	return
@@ -30,18 +49,36 @@ func (c *Client) ChainInfo() (chainInfo *ChainInfoResponse, err error) {
 //
 // For more information: https://www.bitindex.network/developers/api-documentation-v3.html#ChainInfo
 func (c *Client) ChainDifficulty() (difficulty *ChainDifficultyResponse, err error) {
+	return c.ChainDifficultyWithContext(context.Background())
+}
+
+// ChainDifficultyWithContext is the same as ChainDifficulty() but will be canceled when the given context is done
+//
+// For more information: https://www.bitindex.network/developers/api-documentation-v3.html#ChainInfo
+func (c *Client) ChainDifficultyWithContext(ctx context.Context) (difficulty *ChainDifficultyResponse, err error) {
 
 	// Create the request
-	var resp string
+	var resp *requestResponse
 	// /api/v3/network/status
-	resp, err = c.Request("status?q=getDifficulty", http.MethodGet, nil)
+	resp, err = c.request(ctx, &apiRequest{
+		Endpoint:   "status?q=getDifficulty",
+		Idempotent: true,
+		Method:     http.MethodGet,
+		Name:       "ChainDifficulty",
+	})
 	if err != nil {
 		return
 	}
 
+	// Error from request?
+	if resp.StatusCode != http.StatusOK {
+		err = newAPIError(resp)
+		return
+	}
+
 	// Process the response
 	difficulty = new(ChainDifficultyResponse)
-	if err = json.Unmarshal([]byte(resp), difficulty); err != nil {
+	if err = json.Unmarshal(resp.Body, difficulty); err != nil {
 		return
 	}
 	return
@@ -51,18 +88,36 @@ func (c *Client) ChainDifficulty() (difficulty *ChainDifficultyResponse, err err
 //
 // For more information: https://www.bitindex.network/developers/api-documentation-v3.html#ChainInfo
 func (c *Client) ChainBestBlockHash() (bestBlockHash *ChainBestBlockHashResponse, err error) {
+	return c.ChainBestBlockHashWithContext(context.Background())
+}
+
+// ChainBestBlockHashWithContext is the same as ChainBestBlockHash() but will be canceled when the given context is done
+//
+// For more information: https://www.bitindex.network/developers/api-documentation-v3.html#ChainInfo
+func (c *Client) ChainBestBlockHashWithContext(ctx context.Context) (bestBlockHash *ChainBestBlockHashResponse, err error) {
 
 	// Create the request
-	var resp string
+	var resp *requestResponse
 	// /api/v3/network/status
-	resp, err = c.Request("status?q=getBestBlockHash", http.MethodGet, nil)
+	resp, err = c.request(ctx, &apiRequest{
+		Endpoint:   "status?q=getBestBlockHash",
+		Idempotent: true,
+		Method:     http.MethodGet,
+		Name:       "ChainBestBlockHash",
+	})
 	if err != nil {
 		return
 	}
 
+	// Error from request?
+	if resp.StatusCode != http.StatusOK {
+		err = newAPIError(resp)
+		return
+	}
+
 	// Process the response
 	bestBlockHash = new(ChainBestBlockHashResponse)
-	if err = json.Unmarshal([]byte(resp), bestBlockHash); err != nil {
+	if err = json.Unmarshal(resp.Body, bestBlockHash); err != nil {
 		return
 	}
 	return
@@ -72,18 +127,36 @@ func (c *Client) ChainBestBlockHash() (bestBlockHash *ChainBestBlockHashResponse
 //
 // For more information: https://www.bitindex.network/developers/api-documentation-v3.html#ChainInfo
 func (c *Client) ChainLastBlockHash() (lastBlockHash *ChainLastBlockHashResponse, err error) {
+	return c.ChainLastBlockHashWithContext(context.Background())
+}
+
+// ChainLastBlockHashWithContext is the same as ChainLastBlockHash() but will be canceled when the given context is done
+//
+// For more information: https://www.bitindex.network/developers/api-documentation-v3.html#ChainInfo
+func (c *Client) ChainLastBlockHashWithContext(ctx context.Context) (lastBlockHash *ChainLastBlockHashResponse, err error) {
 
 	// Create the request
-	var resp string
+	var resp *requestResponse
 	// /api/v3/network/status
-	resp, err = c.Request("status?q=getLastBlockHash", http.MethodGet, nil)
+	resp, err = c.request(ctx, &apiRequest{
+		Endpoint:   "status?q=getLastBlockHash",
+		Idempotent: true,
+		Method:     http.MethodGet,
+		Name:       "ChainLastBlockHash",
+	})
 	if err != nil {
 		return
 	}
 
+	// Error from request?
+	if resp.StatusCode != http.StatusOK {
+		err = newAPIError(resp)
+		return
+	}
+
 	// Process the response
 	lastBlockHash = new(ChainLastBlockHashResponse)
-	if err = json.Unmarshal([]byte(resp), lastBlockHash); err != nil {
+	if err = json.Unmarshal(resp.Body, lastBlockHash); err != nil {
 		return
 	}
 	return
@@ -4,13 +4,8 @@ import "testing"
 
 // TestClient_ChainInfo tests the ChainInfo()
 func TestClient_ChainInfo(t *testing.T) {
-	// Skip this test in short mode (not needed)
-	if testing.Short() {
-		t.Skip("skipping testing in short mode")
-	}
-
-	// Create a new client object to handle your queries (supply an API Key)
-	client, err := NewClient(testAPIKey, NetworkMain, nil)
+	// Create a new client object to handle your queries (replays the cassette if recorded)
+	client, err := newCassetteClient(t, NetworkMain)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -28,13 +23,8 @@ func TestClient_ChainInfo(t *testing.T) {
 
 // TestClient_ChainDifficulty tests the ChainDifficulty()
 func TestClient_ChainDifficulty(t *testing.T) {
-	// Skip this test in short mode (not needed)
-	if testing.Short() {
-		t.Skip("skipping testing in short mode")
-	}
-
-	// Create a new client object to handle your queries (supply an API Key)
-	client, err := NewClient(testAPIKey, NetworkMain, nil)
+	// Create a new client object to handle your queries (replays the cassette if recorded)
+	client, err := newCassetteClient(t, NetworkMain)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -52,13 +42,8 @@ func TestClient_ChainDifficulty(t *testing.T) {
 
 // TestClient_ChainBestBlockHash tests the ChainBestBlockHash()
 func TestClient_ChainBestBlockHash(t *testing.T) {
-	// Skip this test in short mode (not needed)
-	if testing.Short() {
-		t.Skip("skipping testing in short mode")
-	}
-
-	// Create a new client object to handle your queries (supply an API Key)
-	client, err := NewClient(testAPIKey, NetworkMain, nil)
+	// Create a new client object to handle your queries (replays the cassette if recorded)
+	client, err := newCassetteClient(t, NetworkMain)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -76,13 +61,8 @@ func TestClient_ChainBestBlockHash(t *testing.T) {
 
 // TestClient_ChainLastBlockHash tests the ChainLastBlockHash()
 func TestClient_ChainLastBlockHash(t *testing.T) {
-	// Skip this test in short mode (not needed)
-	if testing.Short() {
-		t.Skip("skipping testing in short mode")
-	}
-
-	// Create a new client object to handle your queries (supply an API Key)
-	client, err := NewClient(testAPIKey, NetworkMain, nil)
+	// Create a new client object to handle your queries (replays the cassette if recorded)
+	client, err := newCassetteClient(t, NetworkMain)
 	if err != nil {
 		t.Fatal(err)
 	}
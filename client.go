@@ -1,12 +1,15 @@
 package bitindex
 
 import (
+	"fmt"
 	"net"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gojektech/heimdall/v6"
-	"github.com/gojektech/heimdall/v6/httpclient"
 )
 
 const (
@@ -17,32 +20,52 @@ const (
 	// defaultUserAgent is the default user agent for all requests
 	defaultUserAgent string = "go-bitindex: " + version
 
-	// apiEndpoint is where we fire requests
-	apiEndpoint string = "https://api.bitindex.network/api/" + version + "/"
+	// defaultAPIBaseURL is where we fire requests (without the version)
+	defaultAPIBaseURL string = "https://api.bitindex.network/api/"
 )
 
-// Client is the parent struct that wraps the heimdall client
+// Client is the parent struct that wraps the http client
+//
+// The client is safe for concurrent use. LastRequest is only a debugging snapshot of the most
+// recent request: read it with GetLastRequest(), or set it to nil to disable tracking
 type Client struct {
-	httpClient  heimdall.Client // carries out the http operations
-	LastRequest *LastRequest    // is the raw information from the last request
-	Parameters  *Parameters     // contains application specific values
+	apiEndpoint     string           // is the base url + version where we fire requests
+	backOff         heimdall.Backoff // is the back-off strategy between retries
+	httpClient      heimdall.Doer    // carries out the http operations
+	LastRequest     *LastRequest     // is the raw information from the last request
+	lastRequestLock sync.Mutex       // guards the LastRequest snapshot
+	Parameters      *Parameters      // contains application specific values
+	rateLimiter     *rateLimiter     // is shared by all requests (and goroutines) of the client
+	requestHooks    []RequestHook    // are called for every request attempt (logging, metrics, tracing)
+	retryCount      int              // is the number of retries for transient failures
 }
 
 // Options holds all the configuration for connection, dialer and transport
+//
+// Provide your own HTTPDoer, HTTPClient or Transport to use a shared (instrumented) client,
+// the dialer & transport options are only applied when none of those are provided
 type Options struct {
-	BackOffExponentFactor          float64       `json:"back_off_exponent_factor"`
-	BackOffInitialTimeout          time.Duration `json:"back_off_initial_timeout"`
-	BackOffMaximumJitterInterval   time.Duration `json:"back_off_maximum_jitter_interval"`
-	BackOffMaxTimeout              time.Duration `json:"back_off_max_timeout"`
-	DialerKeepAlive                time.Duration `json:"dialer_keep_alive"`
-	DialerTimeout                  time.Duration `json:"dialer_timeout"`
-	RequestRetryCount              int           `json:"request_retry_count"`
-	RequestTimeout                 time.Duration `json:"request_timeout"`
-	TransportExpectContinueTimeout time.Duration `json:"transport_expect_continue_timeout"`
-	TransportIdleTimeout           time.Duration `json:"transport_idle_timeout"`
-	TransportMaxIdleConnections    int           `json:"transport_max_idle_connections"`
-	TransportTLSHandshakeTimeout   time.Duration `json:"transport_tls_handshake_timeout"`
-	UserAgent                      string        `json:"user_agent"`
+	APIBaseURL                     string            `json:"api_base_url"`
+	APIVersion                     string            `json:"api_version"`
+	BackOffExponentFactor          float64           `json:"back_off_exponent_factor"`
+	BackOffInitialTimeout          time.Duration     `json:"back_off_initial_timeout"`
+	BackOffMaximumJitterInterval   time.Duration     `json:"back_off_maximum_jitter_interval"`
+	BackOffMaxTimeout              time.Duration     `json:"back_off_max_timeout"`
+	DialerKeepAlive                time.Duration     `json:"dialer_keep_alive"`
+	DialerTimeout                  time.Duration     `json:"dialer_timeout"`
+	HTTPClient                     *http.Client      `json:"-"`                // (optional) use your own http client
+	HTTPDoer                       heimdall.Doer     `json:"-"`                // (optional) use your own heimdall.Doer
+	RateLimit                      float64           `json:"rate_limit"`       // requests per second (0 is unlimited)
+	RateLimitBurst                 int               `json:"rate_limit_burst"` // requests allowed at once
+	RequestHooks                   []RequestHook     `json:"-"`                // (optional) logging, metrics, tracing
+	RequestRetryCount              int               `json:"request_retry_count"`
+	RequestTimeout                 time.Duration     `json:"request_timeout"`
+	Transport                      http.RoundTripper `json:"-"` // (optional) use your own transport
+	TransportExpectContinueTimeout time.Duration     `json:"transport_expect_continue_timeout"`
+	TransportIdleTimeout           time.Duration     `json:"transport_idle_timeout"`
+	TransportMaxIdleConnections    int               `json:"transport_max_idle_connections"`
+	TransportTLSHandshakeTimeout   time.Duration     `json:"transport_tls_handshake_timeout"`
+	UserAgent                      string            `json:"user_agent"`
 }
 
 // LastRequest is used to track what was submitted via the Request()
@@ -64,12 +87,16 @@ type Parameters struct {
 // Useful for starting with the default and then modifying as needed
 func ClientDefaultOptions() (clientOptions *Options) {
 	return &Options{
+		APIBaseURL:                     defaultAPIBaseURL,
+		APIVersion:                     version,
 		BackOffExponentFactor:          2.0,
-		BackOffInitialTimeout:          2 * time.Millisecond,
-		BackOffMaximumJitterInterval:   2 * time.Millisecond,
-		BackOffMaxTimeout:              10 * time.Millisecond,
+		BackOffInitialTimeout:          100 * time.Millisecond,
+		BackOffMaximumJitterInterval:   50 * time.Millisecond,
+		BackOffMaxTimeout:              2 * time.Second,
 		DialerKeepAlive:                20 * time.Second,
 		DialerTimeout:                  5 * time.Second,
+		RateLimit:                      0,
+		RateLimitBurst:                 1,
 		RequestRetryCount:              2,
 		RequestTimeout:                 10 * time.Second,
 		TransportExpectContinueTimeout: 3 * time.Second,
@@ -81,16 +108,76 @@ func ClientDefaultOptions() (clientOptions *Options) {
 }
 
 // createClient will make a new http client based on the options provided
-func createClient(options *Options) (c *Client) {
-
-	// Create a client
-	c = new(Client)
+func createClient(options *Options) (c *Client, err error) {
 
 	// Set options (either default or user modified)
 	if options == nil {
 		options = ClientDefaultOptions()
 	}
 
+	// Create a client
+	c = new(Client)
+
+	// Set the api endpoint (base url + version)
+	if c.apiEndpoint, err = buildAPIEndpoint(options.APIBaseURL, options.APIVersion); err != nil {
+		return nil, err
+	}
+
+	// Use the caller's http client (or build one from the options)
+	c.httpClient = newHTTPDoer(options)
+
+	// Set the retry policy (only transient failures of idempotent requests are retried)
+	if options.RequestRetryCount > 0 {
+		c.retryCount = options.RequestRetryCount
+	}
+
+	// Create exponential back-off (max is never below the initial timeout)
+	backOffMaxTimeout := options.BackOffMaxTimeout
+	if backOffMaxTimeout < options.BackOffInitialTimeout {
+		backOffMaxTimeout = options.BackOffInitialTimeout
+	}
+	c.backOff = heimdall.NewExponentialBackoff(
+		options.BackOffInitialTimeout,
+		backOffMaxTimeout,
+		options.BackOffExponentFactor,
+		options.BackOffMaximumJitterInterval,
+	)
+
+	// Create the rate limiter (shared across goroutines using this client)
+	c.rateLimiter = newRateLimiter(options.RateLimit, options.RateLimitBurst)
+
+	// Set the request hooks
+	c.requestHooks = append(c.requestHooks, options.RequestHooks...)
+
+	// Create a last request and parameters struct
+	c.LastRequest = new(LastRequest)
+	c.Parameters = &Parameters{
+		UserAgent: options.UserAgent,
+	}
+	return
+}
+
+// newHTTPDoer will return the http client that fires the requests
+//
+// Priority: Options.HTTPDoer, Options.HTTPClient, Options.Transport and finally a new http client
+// built from the dialer & transport options (only used when no client or transport is provided)
+func newHTTPDoer(options *Options) heimdall.Doer {
+
+	// Caller provided a client
+	if options.HTTPDoer != nil {
+		return options.HTTPDoer
+	} else if options.HTTPClient != nil {
+		return options.HTTPClient
+	}
+
+	// Caller provided a transport
+	if options.Transport != nil {
+		return &http.Client{
+			Transport: options.Transport,
+			Timeout:   options.RequestTimeout,
+		}
+	}
+
 	// dial is the net dialer for clientDefaultTransport
 	dial := &net.Dialer{KeepAlive: options.DialerKeepAlive, Timeout: options.DialerTimeout}
 
@@ -104,39 +191,40 @@ func createClient(options *Options) (c *Client) {
 		TLSHandshakeTimeout:   options.TransportTLSHandshakeTimeout,
 	}
 
-	// Determine the strategy for the http client (no retry enabled)
-	if options.RequestRetryCount <= 0 {
-		c.httpClient = httpclient.NewClient(
-			httpclient.WithHTTPTimeout(options.RequestTimeout),
-			httpclient.WithHTTPClient(&http.Client{
-				Transport: clientDefaultTransport,
-				Timeout:   options.RequestTimeout,
-			}),
-		)
-	} else { // Retry enabled
-		// Create exponential back-off
-		backOff := heimdall.NewExponentialBackoff(
-			options.BackOffInitialTimeout,
-			options.BackOffMaxTimeout,
-			options.BackOffExponentFactor,
-			options.BackOffMaximumJitterInterval,
-		)
-
-		c.httpClient = httpclient.NewClient(
-			httpclient.WithHTTPTimeout(options.RequestTimeout),
-			httpclient.WithRetrier(heimdall.NewRetrier(backOff)),
-			httpclient.WithRetryCount(options.RequestRetryCount),
-			httpclient.WithHTTPClient(&http.Client{
-				Transport: clientDefaultTransport,
-				Timeout:   options.RequestTimeout,
-			}),
-		)
+	return &http.Client{
+		Transport: clientDefaultTransport,
+		Timeout:   options.RequestTimeout,
 	}
+}
 
-	// Create a last request and parameters struct
-	c.LastRequest = new(LastRequest)
-	c.Parameters = &Parameters{
-		UserAgent: options.UserAgent,
+// buildAPIEndpoint will validate the base url and version and return the api endpoint
+// Empty values will use the defaults (IE: https://api.bitindex.network/api/v3/)
+func buildAPIEndpoint(baseURL, apiVersion string) (string, error) {
+
+	// Set the defaults
+	if baseURL = strings.TrimSpace(baseURL); len(baseURL) == 0 {
+		baseURL = defaultAPIBaseURL
 	}
-	return
+	if apiVersion = strings.Trim(strings.TrimSpace(apiVersion), "/"); len(apiVersion) == 0 {
+		apiVersion = version
+	}
+
+	// Validate the base url
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid api base url: %w", err)
+	} else if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("invalid api base url: scheme must be http or https: %s", baseURL)
+	} else if len(u.Host) == 0 {
+		return "", fmt.Errorf("invalid api base url: missing host: %s", baseURL)
+	} else if len(u.RawQuery) > 0 || len(u.Fragment) > 0 {
+		return "", fmt.Errorf("invalid api base url: query and fragment are not allowed: %s", baseURL)
+	}
+
+	// Validate the version (single path segment)
+	if strings.ContainsAny(apiVersion, "/?#") {
+		return "", fmt.Errorf("invalid api version: %s", apiVersion)
+	}
+
+	return strings.TrimRight(baseURL, "/") + "/" + apiVersion + "/", nil
 }
@@ -0,0 +1,210 @@
+package bitindex
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// CoinSelectionStrategy is how SelectCoins() picks the UTXOs
+type CoinSelectionStrategy string
+
+// Coin selection strategies
+const (
+	SelectBranchAndBound CoinSelectionStrategy = "branch-and-bound" // exact match without change (falls back to largest-first)
+	SelectLargestFirst   CoinSelectionStrategy = "largest-first"    // fewest inputs
+	SelectOldestFirst    CoinSelectionStrategy = "oldest-first"     // most confirmations first
+	SelectSmallestFirst  CoinSelectionStrategy = "smallest-first"   // consolidates small UTXOs
+)
+
+const (
+	// DefaultDustLimit is the smallest change output, smaller change is added to the fee
+	DefaultDustLimit Satoshis = 546
+
+	// branchAndBoundMaxTries is the limit of the branch and bound search
+	branchAndBoundMaxTries = 100000
+
+	// P2PKH size estimates (in bytes)
+	p2pkhInputSize  = 148
+	p2pkhOutputSize = 34
+	txOverheadSize  = 10 // version, input & output counts, lock time
+)
+
+// ErrInsufficientFunds is returned when the UTXOs can not cover the amount and the fee
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// CoinSelectionOptions are the options for SelectCoins()
+type CoinSelectionOptions struct {
+	DustLimit          Satoshis // is the smallest change output (0 uses DefaultDustLimit)
+	ExcludeUnconfirmed bool     // is set to only use UTXOs with confirmations
+	Outputs            int      // is the number of payment outputs for the size estimate (0 uses 1)
+}
+
+// CoinSelection is the result of SelectCoins()
+type CoinSelection struct {
+	Change   Satoshis              // is the change (0 if there is no change output)
+	Fee      Satoshis              // is the fee (including any dust that was not worth a change output)
+	Inputs   UnspentTransactions   // are the selected UTXOs
+	Size     int                   // is the estimated transaction size in bytes
+	Strategy CoinSelectionStrategy // is the strategy that made the selection
+	Total    Satoshis              // is the total of the inputs
+}
+
+// SelectCoins will pick the UTXOs to pay the amount with the strategy at the fee rate (satoshis per kB)
+//
+// Sizes are estimated for P2PKH inputs & outputs, a change output is added when the change is
+// at least the dust limit. UTXOs that cost more in fees than they are worth are not used
+func (u UnspentTransactions) SelectCoins(strategy CoinSelectionStrategy, amount, feePerKB Satoshis, options *CoinSelectionOptions) (selection *CoinSelection, err error) {
+	if amount <= 0 || feePerKB < 0 {
+		err = fmt.Errorf("%w: the amount must be positive and the fee rate can not be negative", ErrInvalidAmount)
+		return
+	}
+	if options == nil {
+		options = &CoinSelectionOptions{}
+	}
+	selector := &coinSelector{dustLimit: options.DustLimit, feePerKB: feePerKB, outputs: options.Outputs}
+	if selector.dustLimit <= 0 {
+		selector.dustLimit = DefaultDustLimit
+	}
+	if selector.outputs <= 0 {
+		selector.outputs = 1
+	}
+
+	// Only spendable & economic UTXOs (a copy, the list is not changed)
+	var candidates UnspentTransactions
+	for _, utxo := range u {
+		if (!options.ExcludeUnconfirmed || utxo.Confirmations > 0) && utxo.satoshis() > selector.fee(p2pkhInputSize) {
+			candidates = append(candidates, utxo)
+		}
+	}
+
+	// Order the candidates for the strategy
+	switch strategy {
+	case SelectBranchAndBound:
+		if selection = selector.branchAndBound(candidates, amount); selection != nil {
+			return
+		}
+		strategy = SelectLargestFirst
+		fallthrough
+	case SelectLargestFirst:
+		sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].satoshis() > candidates[j].satoshis() })
+	case SelectOldestFirst:
+		sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Confirmations > candidates[j].Confirmations })
+	case SelectSmallestFirst:
+		sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].satoshis() < candidates[j].satoshis() })
+	default:
+		err = fmt.Errorf("unknown coin selection strategy: %s", strategy)
+		return
+	}
+
+	// Add inputs until the amount and the fee are covered
+	var total Satoshis
+	for i, utxo := range candidates {
+		total = total.Add(utxo.satoshis())
+		if selection = selector.finish(candidates[:i+1:i+1], total, amount); selection != nil {
+			selection.Strategy = strategy
+			return
+		}
+	}
+
+	err = fmt.Errorf("%w: need %s BSV (plus fees) and %s BSV is available", ErrInsufficientFunds, amount, total)
+	return
+}
+
+// coinSelector has the fee settings for a selection
+type coinSelector struct {
+	dustLimit Satoshis // is the smallest change output
+	feePerKB  Satoshis // is the fee rate
+	outputs   int      // is the number of payment outputs
+}
+
+// fee returns the fee for the size in bytes (rounded up)
+func (s *coinSelector) fee(size int) Satoshis {
+	return (Satoshis(size)*s.feePerKB + 999) / 1000
+}
+
+// size returns the estimated transaction size
+func (s *coinSelector) size(inputs int, change bool) int {
+	outputs := s.outputs
+	if change {
+		outputs++
+	}
+	return txOverheadSize + inputs*p2pkhInputSize + outputs*p2pkhOutputSize
+}
+
+// finish returns the selection if the inputs cover the amount and the fee (nil if not)
+func (s *coinSelector) finish(inputs UnspentTransactions, total, amount Satoshis) *CoinSelection {
+
+	// With a change output
+	size := s.size(len(inputs), true)
+	if change := total - amount - s.fee(size); change >= s.dustLimit {
+		return &CoinSelection{Change: change, Fee: s.fee(size), Inputs: inputs, Size: size, Total: total}
+	}
+
+	// Without a change output (the rest goes to the fee)
+	size = s.size(len(inputs), false)
+	if total-amount >= s.fee(size) {
+		return &CoinSelection{Fee: total - amount, Inputs: inputs, Size: size, Total: total}
+	}
+	return nil
+}
+
+// branchAndBound searches for inputs that pay the amount without a change output
+//
+// Inputs are counted at their effective value (value minus the fee to spend them), a match
+// can overpay by at most the cost of a change output (that excess goes to the fee)
+func (s *coinSelector) branchAndBound(candidates UnspentTransactions, amount Satoshis) *CoinSelection {
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].satoshis() > candidates[j].satoshis() })
+
+	inputFee := s.fee(p2pkhInputSize)
+	effective := make([]Satoshis, len(candidates))
+	var available Satoshis
+	for i := range candidates {
+		effective[i] = candidates[i].satoshis() - inputFee
+		available += effective[i]
+	}
+
+	target := amount + s.fee(s.size(0, false))
+	upper := target + s.dustLimit + s.fee(p2pkhOutputSize)
+
+	// Depth first (include, then exclude), keeping the match with the least excess
+	var best []bool
+	var bestTotal Satoshis
+	selected := make([]bool, len(candidates))
+	tries := 0
+	var search func(index int, total, remaining Satoshis)
+	search = func(index int, total, remaining Satoshis) {
+		tries++
+		if tries > branchAndBoundMaxTries || total > upper || total+remaining < target {
+			return
+		}
+		if total >= target {
+			if best == nil || total < bestTotal {
+				best, bestTotal = append([]bool(nil), selected...), total
+			}
+			return
+		}
+		if index == len(candidates) {
+			return
+		}
+		selected[index] = true
+		search(index+1, total+effective[index], remaining-effective[index])
+		selected[index] = false
+		search(index+1, total, remaining-effective[index])
+	}
+	search(0, 0, available)
+	if best == nil {
+		return nil
+	}
+
+	selection := &CoinSelection{Strategy: SelectBranchAndBound}
+	for i, ok := range best {
+		if ok {
+			selection.Inputs = append(selection.Inputs, candidates[i])
+			selection.Total += candidates[i].satoshis()
+		}
+	}
+	selection.Size = s.size(len(selection.Inputs), false)
+	selection.Fee = selection.Total - amount
+	return selection
+}
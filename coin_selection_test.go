@@ -0,0 +1,155 @@
+package bitindex
+
+import (
+	"errors"
+	"testing"
+)
+
+// testCoins returns UTXOs of 1000, 5000, 20000 (unconfirmed) and 50000 satoshis
+func testCoins() UnspentTransactions {
+	return UnspentTransactions{
+		{Amount: 5000, Confirmations: 10, TxID: "5k"},
+		{Amount: 50000, Confirmations: 2, TxID: "50k"},
+		{Amount: 1000, Confirmations: 100, TxID: "1k"},
+		{Amount: 20000, Confirmations: 0, TxID: "20k"},
+	}
+}
+
+// selectedTxIDs returns the txids of the selected inputs
+func selectedTxIDs(selection *CoinSelection) (txIDs []string) {
+	for _, utxo := range selection.Inputs {
+		txIDs = append(txIDs, utxo.TxID)
+	}
+	return
+}
+
+// TestUnspentTransactions_SelectCoins tests the accumulative strategies
+func TestUnspentTransactions_SelectCoins(t *testing.T) {
+	t.Parallel()
+
+	var tests = []struct {
+		strategy CoinSelectionStrategy
+		amount   Satoshis
+		expected []string
+	}{
+		{SelectLargestFirst, 10000, []string{"50k"}},
+		{SelectLargestFirst, 60000, []string{"50k", "20k"}},
+		{SelectSmallestFirst, 10000, []string{"1k", "5k", "20k"}},
+		{SelectOldestFirst, 6000, []string{"1k", "5k", "50k"}},
+	}
+	for _, test := range tests {
+		selection, err := testCoins().SelectCoins(test.strategy, test.amount, 500, nil)
+		if err != nil {
+			t.Fatalf("%s %d: %v", test.strategy, test.amount, err)
+		}
+		txIDs := selectedTxIDs(selection)
+		if len(txIDs) != len(test.expected) {
+			t.Fatalf("%s %d: expected %v got: %v", test.strategy, test.amount, test.expected, txIDs)
+		}
+		for i := range txIDs {
+			if txIDs[i] != test.expected[i] {
+				t.Fatalf("%s %d: expected %v got: %v", test.strategy, test.amount, test.expected, txIDs)
+			}
+		}
+		if selection.Strategy != test.strategy {
+			t.Fatalf("expected the strategy %s got: %s", test.strategy, selection.Strategy)
+		}
+		if selection.Total != test.amount+selection.Fee+selection.Change {
+			t.Fatalf("%s %d: the inputs do not balance: %+v", test.strategy, test.amount, selection)
+		}
+	}
+}
+
+// TestUnspentTransactions_SelectCoins_Fees tests the size, fee and change
+func TestUnspentTransactions_SelectCoins_Fees(t *testing.T) {
+	t.Parallel()
+
+	// 1 input, 2 outputs (payment & change) = 10 + 148 + 68 = 226 bytes at 1000 sat/kB
+	selection, err := testCoins().SelectCoins(SelectLargestFirst, 10000, 1000, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if selection.Size != 226 || selection.Fee != 226 || selection.Change != 50000-10000-226 {
+		t.Fatalf("unexpected selection: %+v", selection)
+	}
+
+	// The change is dust (added to the fee, no change output)
+	selection, err = testCoins().SelectCoins(SelectLargestFirst, 49500, 1000, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if selection.Change != 0 || selection.Fee != 500 || selection.Size != 192 {
+		t.Fatalf("expected no change output got: %+v", selection)
+	}
+
+	// A higher dust limit and more outputs
+	selection, err = testCoins().SelectCoins(SelectLargestFirst, 40000, 1000, &CoinSelectionOptions{DustLimit: 10000, Outputs: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if selection.Change != 0 || selection.Size != 10+148+3*34 {
+		t.Fatalf("expected no change output for 3 outputs got: %+v", selection)
+	}
+}
+
+// TestUnspentTransactions_SelectCoins_BranchAndBound tests the exact match search
+func TestUnspentTransactions_SelectCoins_BranchAndBound(t *testing.T) {
+	t.Parallel()
+
+	// 5000 + 1000 pays 5500 and the fee for 2 inputs (340 bytes) without change
+	selection, err := testCoins().SelectCoins(SelectBranchAndBound, 5500, 500, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if selection.Strategy != SelectBranchAndBound || selection.Change != 0 || len(selection.Inputs) != 2 {
+		t.Fatalf("expected an exact match got: %+v", selection)
+	}
+	if selection.Fee != 500 || selection.Size != 10+2*148+34 {
+		t.Fatalf("unexpected fee: %+v", selection)
+	}
+
+	// No exact match, falls back to largest-first
+	selection, err = testCoins().SelectCoins(SelectBranchAndBound, 30000, 500, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if selection.Strategy != SelectLargestFirst || selection.Inputs[0].TxID != "50k" || selection.Change == 0 {
+		t.Fatalf("expected the largest-first fallback got: %+v", selection)
+	}
+}
+
+// TestUnspentTransactions_SelectCoins_Errors tests the options and errors
+func TestUnspentTransactions_SelectCoins_Errors(t *testing.T) {
+	t.Parallel()
+
+	coins := testCoins()
+
+	// Unconfirmed outputs
+	if _, err := coins.SelectCoins(SelectLargestFirst, 60000, 500, &CoinSelectionOptions{ExcludeUnconfirmed: true}); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("expected ErrInsufficientFunds got: %v", err)
+	}
+	if coins[3].TxID != "20k" {
+		t.Fatal("expected the list to be unchanged")
+	}
+
+	// Not enough for the fee
+	if _, err := coins.SelectCoins(SelectLargestFirst, 76000, 500, nil); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("expected ErrInsufficientFunds got: %v", err)
+	}
+
+	// Invalid amounts & strategy
+	if _, err := coins.SelectCoins(SelectLargestFirst, 0, 500, nil); !errors.Is(err, ErrInvalidAmount) {
+		t.Fatalf("expected ErrInvalidAmount got: %v", err)
+	}
+	if _, err := coins.SelectCoins(SelectLargestFirst, 1000, -1, nil); !errors.Is(err, ErrInvalidAmount) {
+		t.Fatalf("expected ErrInvalidAmount got: %v", err)
+	}
+	if _, err := coins.SelectCoins("random", 1000, 500, nil); err == nil {
+		t.Fatal("expected an unknown strategy error")
+	}
+
+	// Uneconomic UTXOs are not used (1000 satoshis cost 1480 to spend)
+	if _, err := (UnspentTransactions{{Amount: 1000, TxID: "dust"}}).SelectCoins(SelectLargestFirst, 100, 10000, nil); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("expected ErrInsufficientFunds got: %v", err)
+	}
+}
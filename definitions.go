@@ -38,16 +38,16 @@ type APIErrorResponse struct {
 type AddressInfo struct {
 	APIInternalError
 	Address                    string   `json:"addrStr"`
-	Balance                    float64  `json:"balance"`
-	BalanceSatoshis            int64    `json:"balanceSat"`
-	TotalReceived              float64  `json:"totalReceived"`
-	TotalReceivedSatoshis      int64    `json:"totalReceivedSat"`
-	TotalSent                  float64  `json:"totalSent"`
-	TotalSentSatoshis          int64    `json:"totalSentSat"`
+	Balance                    Satoshis `json:"balance"`
+	BalanceSatoshis            Satoshis `json:"balanceSat"`
+	TotalReceived              Satoshis `json:"totalReceived"`
+	TotalReceivedSatoshis      Satoshis `json:"totalReceivedSat"`
+	TotalSent                  Satoshis `json:"totalSent"`
+	TotalSentSatoshis          Satoshis `json:"totalSentSat"`
 	Transactions               []string `json:"transactions"`
 	TxAppearances              int64    `json:"txApperances"`
-	UnconfirmedBalance         float64  `json:"unconfirmedBalance"`
-	UnconfirmedBalanceSatoshis int64    `json:"unconfirmedBalanceSat"`
+	UnconfirmedBalance         Satoshis `json:"unconfirmedBalance"`
+	UnconfirmedBalanceSatoshis Satoshis `json:"unconfirmedBalanceSat"`
 	UnconfirmedTxAppearances   int64    `json:"unconfirmedTxApperances"`
 }
 
@@ -57,20 +57,20 @@ type UnspentTransactions []UnspentTransaction
 // UnspentTransaction is a standard UTXO response
 // Also has some fields for xpub data (chain, num, path)
 type UnspentTransaction struct {
-	Address       string  `json:"address"`
-	Amount        float64 `json:"amount"`
-	Chain         int     `json:"chain"`
-	Confirmations int64   `json:"confirmations"`
-	Height        int64   `json:"height"`
-	Num           int     `json:"num"`
-	OutputIndex   int64   `json:"outputIndex"`
-	Path          string  `json:"path"`
-	Satoshis      int64   `json:"satoshis"`
-	Script        string  `json:"script"`
-	ScriptPubKey  string  `json:"scriptPubKey"`
-	TxID          string  `json:"txid"`
-	Value         int64   `json:"value"`
-	Vout          int     `json:"vout"`
+	Address       string   `json:"address"`
+	Amount        Satoshis `json:"amount"`
+	Chain         int      `json:"chain"`
+	Confirmations int64    `json:"confirmations"`
+	Height        int64    `json:"height"`
+	Num           int      `json:"num"`
+	OutputIndex   int64    `json:"outputIndex"`
+	Path          string   `json:"path"`
+	Satoshis      Satoshis `json:"satoshis"`
+	Script        string   `json:"script"`
+	ScriptPubKey  string   `json:"scriptPubKey"`
+	TxID          string   `json:"txid"`
+	Value         Satoshis `json:"value"`
+	Vout          int      `json:"vout"`
 }
 
 // GetTransactionsRequest is for making a POST to get transactions
@@ -97,22 +97,22 @@ type GetTransactionsResponse struct {
 // Transaction is returned in the GetTransactionsResponse
 type Transaction struct {
 	APIInternalError
-	BlockHash     string       `json:"blockhash"`
-	BlockHeight   int64        `json:"blockheight"`
-	BlockTime     int64        `json:"blocktime"`
-	Confirmations int64        `json:"confirmations"`
-	Fees          float64      `json:"fees"`
-	Hash          string       `json:"hash"`
-	LockTime      int64        `json:"locktime"`
-	RawTx         string       `json:"rawtx"`
-	Size          int64        `json:"size"`
-	Time          int64        `json:"time"`
-	TxID          string       `json:"txid"`
-	ValueIn       float64      `json:"valueIn"`
-	ValueOut      float64      `json:"valueOut"`
-	Version       int          `json:"version"`
-	Vin           []vinObject  `json:"vin"`
-	Vout          []voutObject `json:"vout"`
+	BlockHash     string              `json:"blockhash"`
+	BlockHeight   int64               `json:"blockheight"`
+	BlockTime     int64               `json:"blocktime"`
+	Confirmations int64               `json:"confirmations"`
+	Fees          Satoshis            `json:"fees"`
+	Hash          string              `json:"hash"`
+	LockTime      int64               `json:"locktime"`
+	RawTx         string              `json:"rawtx"`
+	Size          int64               `json:"size"`
+	Time          int64               `json:"time"`
+	TxID          string              `json:"txid"`
+	ValueIn       Satoshis            `json:"valueIn"`
+	ValueOut      Satoshis            `json:"valueOut"`
+	Version       int                 `json:"version"`
+	Vin           []TransactionInput  `json:"vin"`
+	Vout          []TransactionOutput `json:"vout"`
 }
 
 // TransactionRaw is the response for the raw tx request
@@ -121,38 +121,38 @@ type TransactionRaw struct {
 	RawTx string `json:"rawtx"`
 }
 
-// vinObject is the vin data
-type vinObject struct {
-	Address       string          `json:"address"`
-	AddressAddr   string          `json:"addr"`
-	N             int             `json:"n"`
-	ScriptSig     scriptSigObject `json:"scriptSig"`
-	Sequence      int64           `json:"sequence"`
-	TxID          string          `json:"txid"`
-	Value         float64         `json:"value"`
-	ValueSatoshis int64           `json:"valueSat"`
-	Vout          int             `json:"vout"`
+// TransactionInput is the vin data
+type TransactionInput struct {
+	Address       string    `json:"address"`
+	AddressAddr   string    `json:"addr"`
+	N             int       `json:"n"`
+	ScriptSig     ScriptSig `json:"scriptSig"`
+	Sequence      int64     `json:"sequence"`
+	TxID          string    `json:"txid"`
+	Value         Satoshis  `json:"value"`
+	ValueSatoshis Satoshis  `json:"valueSat"`
+	Vout          int       `json:"vout"`
 }
 
-// scriptSigObject is the script signature data
-type scriptSigObject struct {
+// ScriptSig is the script signature data
+type ScriptSig struct {
 	Asm string `json:"asm"`
 	Hex string `json:"hex"`
 }
 
-// voutObject is the vout data
-type voutObject struct {
-	N             int                `json:"n"`
-	ScriptPubKey  scriptPubKeyObject `json:"scriptPubKey"`
-	SpentHeight   int64              `json:"spentHeight"`
-	SpentIndex    int64              `json:"spentIndex"`
-	SpentTxID     string             `json:"spentTxId"`
-	Value         float64            `json:"value"`
-	ValueSatoshis int64              `json:"valueSat"`
+// TransactionOutput is the vout data
+type TransactionOutput struct {
+	N             int          `json:"n"`
+	ScriptPubKey  ScriptPubKey `json:"scriptPubKey"`
+	SpentHeight   int64        `json:"spentHeight"`
+	SpentIndex    int64        `json:"spentIndex"`
+	SpentTxID     string       `json:"spentTxId"`
+	Value         Satoshis     `json:"value"`
+	ValueSatoshis Satoshis     `json:"valueSat"`
 }
 
-// scriptPubKeyObject is the script pubkey data
-type scriptPubKeyObject struct {
+// ScriptPubKey is the script pubkey data
+type ScriptPubKey struct {
 	Addresses          []string `json:"addresses"`
 	Asm                string   `json:"asm"`
 	Hex                string   `json:"hex"`
@@ -164,7 +164,7 @@ type scriptPubKeyObject struct {
 type GetUnspentTransactionsRequest struct {
 	Address   string   `json:"addrs"` // single address or addr1,addr2,addr3
 	Addresses []string `json:"-"`     // (used for multiple)
-	Sort      string   `json:"sort"`  // Format is 'field:asc' such as 'value:desc' to sort by value descending
+	Sort      UTXOSort `json:"sort"`  // Format is 'field:asc' such as 'value:desc' to sort by value descending (see NewUTXOSort())
 }
 
 // SendTransactionResponse is the response for the request
@@ -175,22 +175,22 @@ type SendTransactionResponse struct {
 
 // ChainInfoResponse response struct for chain info request
 type ChainInfoResponse struct {
-	Info chainInfo `json:"info"`
+	Info ChainInfo `json:"info"`
 }
 
-// chainInfo is for the chain info request response data
-type chainInfo struct {
-	Blocks          int64   `json:"blocks"`
-	Connections     int64   `json:"connections"`
-	Difficulty      float64 `json:"difficulty"`
-	Errors          string  `json:"errors"`
-	Network         string  `json:"network"`
-	ProtocolVersion int64   `json:"protocolversion"`
-	Proxy           string  `json:"proxy"`
-	RelayFee        float64 `json:"relayfee"`
-	TestNet         bool    `json:"testnet"`
-	TimeOffset      int64   `json:"timeoffset"`
-	Version         int64   `json:"version"`
+// ChainInfo is for the chain info request response data
+type ChainInfo struct {
+	Blocks          int64    `json:"blocks"`
+	Connections     int64    `json:"connections"`
+	Difficulty      float64  `json:"difficulty"`
+	Errors          string   `json:"errors"`
+	Network         string   `json:"network"`
+	ProtocolVersion int64    `json:"protocolversion"`
+	Proxy           string   `json:"proxy"`
+	RelayFee        Satoshis `json:"relayfee"` // per kB
+	TestNet         bool     `json:"testnet"`
+	TimeOffset      int64    `json:"timeoffset"`
+	Version         int64    `json:"version"`
 }
 
 // ChainDifficultyResponse response struct for chain difficulty request
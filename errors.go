@@ -0,0 +1,92 @@
+package bitindex
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors for the kind of failure returned by the api, use with errors.Is()
+//
+// Example: if errors.Is(err, bitindex.ErrNotFound) { ... }
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrNotFound     = errors.New("not found")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrServerError  = errors.New("server error")
+	ErrUnauthorized = errors.New("unauthorized")
+)
+
+// APIError is returned by every endpoint when the api responds with a non-success status
+//
+// Use errors.As() to get the details, or errors.Is() with one of the sentinel errors
+type APIError struct {
+	Body       string   `json:"body"`        // is the raw response body
+	Code       int      `json:"code"`        // is the api error code (broadcast related errors)
+	Endpoint   string   `json:"endpoint"`    // is the endpoint that was requested (IE: addr/address)
+	Errors     []string `json:"errors"`      // is the list of errors returned by the api
+	Message    string   `json:"message"`     // is the error message returned by the api
+	Method     string   `json:"method"`      // is the HTTP method used
+	Name       string   `json:"name"`        // is the error name returned by the api
+	StatusCode int      `json:"status_code"` // is the HTTP status code of the response
+}
+
+// newAPIError will create an APIError from the response of a failed request
+func newAPIError(response *requestResponse) *APIError {
+	apiErr := &APIError{
+		Body:       string(response.Body),
+		Endpoint:   response.Endpoint,
+		Method:     response.Method,
+		StatusCode: response.StatusCode,
+	}
+
+	// Both error formats (APIInternalError & APIErrorResponse) share most of the fields
+	var errorResponse struct {
+		APIErrorResponse
+		ErrorName string `json:"name,omitempty"`
+	}
+	if err := json.Unmarshal(response.Body, &errorResponse); err == nil {
+		apiErr.Code = errorResponse.ErrorCode
+		apiErr.Errors = errorResponse.Errors
+		apiErr.Message = errorResponse.ErrorMessage
+		apiErr.Name = errorResponse.ErrorName
+		if len(apiErr.Message) == 0 {
+			apiErr.Message = errorResponse.Error
+		}
+	}
+
+	// Fallback to the errors or the status text
+	if len(apiErr.Message) == 0 {
+		if len(apiErr.Errors) > 0 {
+			apiErr.Message = strings.Join(apiErr.Errors, ", ")
+		} else {
+			apiErr.Message = http.StatusText(response.StatusCode)
+		}
+	}
+
+	return apiErr
+}
+
+// Error returns the error message
+func (e *APIError) Error() string {
+	return fmt.Sprintf("error: %s (status code: %d)", e.Message, e.StatusCode)
+}
+
+// Is will match the error against the sentinel errors using the status code
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServerError:
+		return e.StatusCode >= http.StatusInternalServerError
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package bitindex
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// TestNewAPIError tests the newAPIError()
+func TestNewAPIError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("internal error format", func(t *testing.T) {
+		apiErr := newAPIError(&requestResponse{
+			Body:       []byte(`{"errors":["address not found"],"message":"Not Found","name":"NotFoundError"}`),
+			Endpoint:   "addr/test",
+			Method:     http.MethodGet,
+			StatusCode: http.StatusNotFound,
+		})
+		if apiErr.Message != "Not Found" {
+			t.Fatalf("unexpected message: %s", apiErr.Message)
+		}
+		if apiErr.Name != "NotFoundError" {
+			t.Fatalf("unexpected name: %s", apiErr.Name)
+		}
+		if len(apiErr.Errors) != 1 || apiErr.Errors[0] != "address not found" {
+			t.Fatalf("unexpected errors: %v", apiErr.Errors)
+		}
+		if apiErr.Endpoint != "addr/test" || apiErr.Method != http.MethodGet {
+			t.Fatalf("unexpected endpoint: %s %s", apiErr.Method, apiErr.Endpoint)
+		}
+		if apiErr.Error() != "error: Not Found (status code: 404)" {
+			t.Fatalf("unexpected error string: %s", apiErr.Error())
+		}
+	})
+
+	t.Run("broadcast error format", func(t *testing.T) {
+		apiErr := newAPIError(&requestResponse{
+			Body:       []byte(`{"success":false,"code":422,"error":"txn-mempool-conflict"}`),
+			Endpoint:   "tx/send",
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+		})
+		if apiErr.Message != "txn-mempool-conflict" {
+			t.Fatalf("unexpected message: %s", apiErr.Message)
+		}
+		if apiErr.Code != 422 {
+			t.Fatalf("unexpected code: %d", apiErr.Code)
+		}
+	})
+
+	t.Run("non json body", func(t *testing.T) {
+		apiErr := newAPIError(&requestResponse{
+			Body:       []byte("<html>bad gateway</html>"),
+			Endpoint:   "block/test",
+			Method:     http.MethodGet,
+			StatusCode: http.StatusBadGateway,
+		})
+		if apiErr.Message != http.StatusText(http.StatusBadGateway) {
+			t.Fatalf("unexpected message: %s", apiErr.Message)
+		}
+		if apiErr.Body != "<html>bad gateway</html>" {
+			t.Fatalf("unexpected body: %s", apiErr.Body)
+		}
+	})
+}
+
+// TestAPIError_Is tests the sentinel errors
+func TestAPIError_Is(t *testing.T) {
+	t.Parallel()
+
+	var tests = []struct {
+		statusCode int
+		expected   error
+	}{
+		{http.StatusBadRequest, ErrBadRequest},
+		{http.StatusUnprocessableEntity, ErrBadRequest},
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrUnauthorized},
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{http.StatusInternalServerError, ErrServerError},
+		{http.StatusServiceUnavailable, ErrServerError},
+	}
+
+	sentinels := []error{ErrBadRequest, ErrNotFound, ErrRateLimited, ErrServerError, ErrUnauthorized}
+	for _, test := range tests {
+		var err error = newAPIError(&requestResponse{StatusCode: test.statusCode})
+		for _, sentinel := range sentinels {
+			if is := errors.Is(err, sentinel); is != (sentinel == test.expected) {
+				t.Fatalf("status %d: errors.Is(%v) = %t", test.statusCode, sentinel, is)
+			}
+		}
+	}
+}
+
+// TestClient_AddressInfo_APIError tests the error returned by an endpoint
+func TestClient_AddressInfo_APIError(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"Not Found","name":"NotFoundError","errors":[]}`))
+	}), nil)
+
+	_, err := client.AddressInfo("test")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got: %v", err)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected an APIError, got: %T", err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound || apiErr.Endpoint != "addr/test" {
+		t.Fatalf("unexpected error details: %+v", apiErr)
+	}
+}
+
+// TestBatchError tests the message of the BatchError (with and without failures)
+func TestBatchError(t *testing.T) {
+	t.Parallel()
+
+	err := &BatchError{Batches: 2}
+	if err.Error() != "0 of 2 batches failed" || errors.Is(err, ErrBadRequest) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err.Failures = []BatchFailure{{Addresses: []string{"1abc"}, Err: ErrBadRequest}}
+	if !errors.Is(err, ErrBadRequest) || err.Error() != "1 of 2 batches failed: "+ErrBadRequest.Error() {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
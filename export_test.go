@@ -0,0 +1,24 @@
+package bitindex
+
+import "time"
+
+// SetAddressPoolClock replaces the clock of the pool (for the bitindex_test tests)
+func SetAddressPoolClock(pool *AddressPool, now func() time.Time) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	pool.now = now
+}
+
+// AddressPoolHandedOut returns the number of handed out addresses the pool remembers
+func AddressPoolHandedOut(pool *AddressPool) int {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	return len(pool.handedOut)
+}
+
+// SetAddressStoreClock replaces the clock of the store (for the bitindex_test tests)
+func SetAddressStoreClock(store *MemoryAddressStore, now func() time.Time) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.now = now
+}
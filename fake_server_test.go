@@ -0,0 +1,49 @@
+package bitindex_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mrz1836/go-bitindex"
+	"github.com/mrz1836/go-bitindex/bitindextest"
+)
+
+// serverSeed adds the chain state for a test to the server (IE: xpubAddresses(), xpubHistory())
+type serverSeed func(t *testing.T, server *bitindextest.Server)
+
+// newFakeServer starts a bitindextest server with the seeded chain state and a client for it (nil options uses the defaults)
+//
+// The tests with chain state are in bitindex_test because bitindextest imports bitindex (an internal
+// test can not import it), every other test is in package bitindex with newTestClient()
+func newFakeServer(t *testing.T, options *bitindex.Options, seeds ...serverSeed) (*bitindextest.Server, *bitindex.Client) {
+	server := bitindextest.NewServer()
+	t.Cleanup(server.Close)
+	for _, seed := range seeds {
+		seed(t, server)
+	}
+
+	client, err := server.NewClient(bitindex.NetworkMain, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server, client
+}
+
+// noRetryOptions returns the default options without retries
+func noRetryOptions() *bitindex.Options {
+	options := bitindex.ClientDefaultOptions()
+	options.RequestRetryCount = 0
+	return options
+}
+
+// transactionRequests returns the addrs/txs requests received by the server (in order)
+func transactionRequests(server *bitindextest.Server) (requests []bitindex.GetTransactionsRequest) {
+	for _, request := range server.Requests() {
+		if request.Path == "addrs/txs" {
+			var body bitindex.GetTransactionsRequest
+			_ = json.Unmarshal([]byte(request.Body), &body)
+			requests = append(requests, body)
+		}
+	}
+	return
+}
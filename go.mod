@@ -3,10 +3,12 @@ module github.com/mrz1836/go-bitindex
 go 1.15
 
 require (
+	github.com/btcsuite/btcd v0.21.0-beta
 	github.com/gojektech/heimdall/v6 v6.1.0
 	github.com/gojektech/valkyrie v0.0.0-20190210220504-8f62c1e7ba45 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/stretchr/objx v0.3.0 // indirect
 	github.com/stretchr/testify v1.7.0 // indirect
+	golang.org/x/crypto v0.0.0-20201221181555-eec23a3978ad
 	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b // indirect
 )
@@ -0,0 +1,79 @@
+package bitindex
+
+import (
+	"context"
+	"time"
+)
+
+// RequestInfo is the information about a single request attempt passed to the request hooks
+type RequestInfo struct {
+	Attempt       int           // is the attempt number (0 is the first attempt, > 0 is a retry)
+	Endpoint      string        // is the endpoint requested (IE: addr/address)
+	Error         error         // is the error that occurred (OnError only)
+	Latency       time.Duration // is the time the attempt took (AfterResponse & OnError only)
+	Method        string        // is the HTTP method used
+	Name          string        // is the name of the endpoint (IE: AddressInfo)
+	RequestBytes  int           // is the size of the post data
+	ResponseBytes int           // is the size of the response body (AfterResponse only)
+	StatusCode    int           // is the HTTP status code (AfterResponse only)
+	URL           string        // is the full url used for the request
+}
+
+// RequestHook is called for every attempt of every request (including retries)
+//
+// AfterResponse is called for every response received (any status code), OnError is called
+// when no response was received (connection errors, timeouts, canceled context, etc.)
+type RequestHook interface {
+	AfterResponse(ctx context.Context, info *RequestInfo)
+	BeforeRequest(ctx context.Context, info *RequestInfo)
+	OnError(ctx context.Context, info *RequestInfo)
+}
+
+// RequestHookFuncs is a RequestHook built from functions (nil functions are skipped)
+type RequestHookFuncs struct {
+	AfterResponseFunc func(ctx context.Context, info *RequestInfo)
+	BeforeRequestFunc func(ctx context.Context, info *RequestInfo)
+	OnErrorFunc       func(ctx context.Context, info *RequestInfo)
+}
+
+// AfterResponse calls AfterResponseFunc if set
+func (h *RequestHookFuncs) AfterResponse(ctx context.Context, info *RequestInfo) {
+	if h.AfterResponseFunc != nil {
+		h.AfterResponseFunc(ctx, info)
+	}
+}
+
+// BeforeRequest calls BeforeRequestFunc if set
+func (h *RequestHookFuncs) BeforeRequest(ctx context.Context, info *RequestInfo) {
+	if h.BeforeRequestFunc != nil {
+		h.BeforeRequestFunc(ctx, info)
+	}
+}
+
+// OnError calls OnErrorFunc if set
+func (h *RequestHookFuncs) OnError(ctx context.Context, info *RequestInfo) {
+	if h.OnErrorFunc != nil {
+		h.OnErrorFunc(ctx, info)
+	}
+}
+
+// beforeRequest will run the BeforeRequest hooks
+func (c *Client) beforeRequest(ctx context.Context, info *RequestInfo) {
+	for _, hook := range c.requestHooks {
+		hook.BeforeRequest(ctx, info)
+	}
+}
+
+// afterResponse will run the AfterResponse hooks
+func (c *Client) afterResponse(ctx context.Context, info *RequestInfo) {
+	for _, hook := range c.requestHooks {
+		hook.AfterResponse(ctx, info)
+	}
+}
+
+// onError will run the OnError hooks
+func (c *Client) onError(ctx context.Context, info *RequestInfo) {
+	for _, hook := range c.requestHooks {
+		hook.OnError(ctx, info)
+	}
+}
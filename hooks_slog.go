@@ -0,0 +1,65 @@
+//go:build go1.21
+// +build go1.21
+
+package bitindex
+
+import (
+	"context"
+	"log/slog"
+)
+
+// slogHook is a RequestHook that logs with a structured logger
+type slogHook struct {
+	logger *slog.Logger
+}
+
+// NewSlogHook returns a RequestHook that logs every request attempt with the structured logger
+//
+// Before a request is logged at debug level, responses at info level (warn for >= 400)
+// and errors at error level (nil logger uses slog.Default())
+func NewSlogHook(logger *slog.Logger) RequestHook {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &slogHook{logger: logger}
+}
+
+// BeforeRequest logs the request at debug level
+func (h *slogHook) BeforeRequest(ctx context.Context, info *RequestInfo) {
+	h.logger.LogAttrs(ctx, slog.LevelDebug, "bitindex request",
+		slog.String("endpoint", info.Name),
+		slog.String("method", info.Method),
+		slog.String("path", info.Endpoint),
+		slog.Int("attempt", info.Attempt),
+		slog.Int("request_bytes", info.RequestBytes),
+	)
+}
+
+// AfterResponse logs the response at info level (warn for >= 400)
+func (h *slogHook) AfterResponse(ctx context.Context, info *RequestInfo) {
+	level := slog.LevelInfo
+	if info.StatusCode >= 400 {
+		level = slog.LevelWarn
+	}
+	h.logger.LogAttrs(ctx, level, "bitindex response",
+		slog.String("endpoint", info.Name),
+		slog.String("method", info.Method),
+		slog.String("path", info.Endpoint),
+		slog.Int("status", info.StatusCode),
+		slog.Duration("latency", info.Latency),
+		slog.Int("attempt", info.Attempt),
+		slog.Int("response_bytes", info.ResponseBytes),
+	)
+}
+
+// OnError logs the error at error level
+func (h *slogHook) OnError(ctx context.Context, info *RequestInfo) {
+	h.logger.LogAttrs(ctx, slog.LevelError, "bitindex request failed",
+		slog.String("endpoint", info.Name),
+		slog.String("method", info.Method),
+		slog.String("path", info.Endpoint),
+		slog.Duration("latency", info.Latency),
+		slog.Int("attempt", info.Attempt),
+		slog.Any("error", info.Error),
+	)
+}
@@ -0,0 +1,41 @@
+//go:build go1.21
+// +build go1.21
+
+package bitindex
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestNewSlogHook tests the structured logging hook
+func TestNewSlogHook(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	options := ClientDefaultOptions()
+	options.RequestHooks = []RequestHook{NewSlogHook(logger)}
+
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}), options)
+
+	if _, err := client.GetBlock("test"); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	output := buf.String()
+	for _, expected := range []string{
+		`level=DEBUG msg="bitindex request" endpoint=GetBlock method=GET path=block/test attempt=0`,
+		`level=WARN msg="bitindex response" endpoint=GetBlock method=GET path=block/test status=404`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Fatalf("missing log: %s\n%s", expected, output)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package bitindex
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+// recordingHook records every hook call
+type recordingHook struct {
+	mu     sync.Mutex
+	events []string
+	infos  []RequestInfo
+}
+
+// record will store the event and a copy of the info
+func (r *recordingHook) record(event string, info *RequestInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	r.infos = append(r.infos, *info)
+}
+
+// newRecordingHook returns a RequestHookFuncs that records to the recordingHook
+func newRecordingHook(r *recordingHook) *RequestHookFuncs {
+	return &RequestHookFuncs{
+		AfterResponseFunc: func(_ context.Context, info *RequestInfo) { r.record("after", info) },
+		BeforeRequestFunc: func(_ context.Context, info *RequestInfo) { r.record("before", info) },
+		OnErrorFunc:       func(_ context.Context, info *RequestInfo) { r.record("error", info) },
+	}
+}
+
+// TestClient_RequestHooks tests the request hooks
+func TestClient_RequestHooks(t *testing.T) {
+	t.Parallel()
+
+	t.Run("response with retry", func(t *testing.T) {
+		hook := new(recordingHook)
+		options := newRetryOptions(1)
+		options.RequestHooks = []RequestHook{newRecordingHook(hook)}
+
+		var mu sync.Mutex
+		var requests int
+		client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			requests++
+			first := requests == 1
+			mu.Unlock()
+			if first {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte(`{"txid":"test"}`))
+		}), options)
+
+		if _, err := client.GetTransaction("test"); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []string{"before", "after", "before", "after"}
+		if len(hook.events) != len(expected) {
+			t.Fatalf("expected events %v, got: %v", expected, hook.events)
+		}
+		for i, event := range expected {
+			if hook.events[i] != event {
+				t.Fatalf("expected events %v, got: %v", expected, hook.events)
+			}
+		}
+
+		first, last := hook.infos[1], hook.infos[3]
+		if first.StatusCode != http.StatusServiceUnavailable || first.Attempt != 0 {
+			t.Fatalf("unexpected first attempt: %+v", first)
+		}
+		if last.StatusCode != http.StatusOK || last.Attempt != 1 {
+			t.Fatalf("unexpected last attempt: %+v", last)
+		}
+		if last.Name != "GetTransaction" || last.Endpoint != "tx/test" || last.Method != http.MethodGet {
+			t.Fatalf("unexpected request info: %+v", last)
+		}
+		if last.ResponseBytes != len(`{"txid":"test"}`) || last.Latency <= 0 {
+			t.Fatalf("unexpected response info: %+v", last)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		hook := new(recordingHook)
+		options := newRetryOptions(0)
+		options.RequestHooks = []RequestHook{newRecordingHook(hook)}
+
+		// Cancel once the request arrives
+		ctx, cancel := context.WithCancel(context.Background())
+		client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.Copy(ioutil.Discard, r.Body)
+			cancel()
+			<-r.Context().Done()
+		}), options)
+
+		if _, err := client.SendTransactionWithContext(ctx, testRawTx); err == nil {
+			t.Fatal("expected an error")
+		}
+
+		hook.mu.Lock()
+		defer hook.mu.Unlock()
+		if len(hook.events) == 0 || hook.events[len(hook.events)-1] != "error" {
+			t.Fatalf("expected an error event, got: %v", hook.events)
+		}
+		if info := hook.infos[len(hook.infos)-1]; info.Error == nil || info.RequestBytes == 0 {
+			t.Fatalf("unexpected error info: %+v", info)
+		}
+	})
+}
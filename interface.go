@@ -0,0 +1,102 @@
+package bitindex
+
+import "context"
+
+// AddressService is the BitIndex address related requests
+type AddressService interface {
+	AddressInfo(address string) (addressInfo *AddressInfo, err error)
+	AddressInfoWithContext(ctx context.Context, address string) (addressInfo *AddressInfo, err error)
+	AddressUnspentTransactions(address string) (transactions UnspentTransactions, err error)
+	AddressUnspentTransactionsWithContext(ctx context.Context, address string) (transactions UnspentTransactions, err error)
+	GetTransactions(transactionRequest *GetTransactionsRequest) (response *GetTransactionsResponse, err error)
+	GetTransactionsWithContext(ctx context.Context, transactionRequest *GetTransactionsRequest) (response *GetTransactionsResponse, err error)
+	GetUnspentTransactions(transactionRequest *GetUnspentTransactionsRequest) (transactions UnspentTransactions, err error)
+	GetUnspentTransactionsWithContext(ctx context.Context, transactionRequest *GetUnspentTransactionsRequest) (transactions UnspentTransactions, err error)
+}
+
+// XpubService is the BitIndex xpub related requests
+type XpubService interface {
+	GetXpubAddressesPage(xPub string, options *GetXpubAddressesOptions) (addresses XpubAddresses, err error)
+	GetXpubAddressesPageWithContext(ctx context.Context, xPub string, options *GetXpubAddressesOptions) (addresses XpubAddresses, err error)
+	GetXpubBalance(xPub string) (balance *XpubBalance, err error)
+	GetXpubBalanceWithContext(ctx context.Context, xPub string) (balance *XpubBalance, err error)
+	GetXpubNextAddress(xPub string, reserveTimeSeconds int) (addresses XpubAddresses, err error)
+	GetXpubNextAddressWithContext(ctx context.Context, xPub string, reserveTimeSeconds int) (addresses XpubAddresses, err error)
+	GetXpubTransactions(xPub string) (transactions XpubAddresses, err error)
+	GetXpubTransactionsWithContext(ctx context.Context, xPub string) (transactions XpubAddresses, err error)
+	GetXpubUnspentTransactionsSorted(xPub string, sort UTXOSort) (transactions UnspentTransactions, err error)
+	GetXpubUnspentTransactionsSortedWithContext(ctx context.Context, xPub string, sort UTXOSort) (transactions UnspentTransactions, err error)
+}
+
+// XpubTransactionService is the BitIndex requests used by GetXpubTransactionDetails()
+type XpubTransactionService interface {
+	ChainService
+	TransactionService
+	XpubService
+}
+
+// BlockService is the BitIndex block related requests
+type BlockService interface {
+	GetBlock(hash string) (block *BlockResponse, err error)
+	GetBlockWithContext(ctx context.Context, hash string) (block *BlockResponse, err error)
+	GetBlockHashByHeight(height int64) (blockHash *BlockHashByHeightResponse, err error)
+	GetBlockHashByHeightWithContext(ctx context.Context, height int64) (blockHash *BlockHashByHeightResponse, err error)
+	GetBlockHeader(hash string) (blockHeader *BlockHeaderResponse, err error)
+	GetBlockHeaderWithContext(ctx context.Context, hash string) (blockHeader *BlockHeaderResponse, err error)
+	GetBlockRaw(hash string) (rawBlock *BlockRawResponse, err error)
+	GetBlockRawReader(hash string) (reader *BlockReader, err error)
+	GetBlockRawReaderWithContext(ctx context.Context, hash string) (reader *BlockReader, err error)
+	GetBlockRawWithContext(ctx context.Context, hash string) (rawBlock *BlockRawResponse, err error)
+}
+
+// ChainService is the BitIndex chain info related requests
+type ChainService interface {
+	ChainBestBlockHash() (bestBlockHash *ChainBestBlockHashResponse, err error)
+	ChainBestBlockHashWithContext(ctx context.Context) (bestBlockHash *ChainBestBlockHashResponse, err error)
+	ChainDifficulty() (difficulty *ChainDifficultyResponse, err error)
+	ChainDifficultyWithContext(ctx context.Context) (difficulty *ChainDifficultyResponse, err error)
+	ChainInfo() (chainInfo *ChainInfoResponse, err error)
+	ChainInfoWithContext(ctx context.Context) (chainInfo *ChainInfoResponse, err error)
+	ChainLastBlockHash() (lastBlockHash *ChainLastBlockHashResponse, err error)
+	ChainLastBlockHashWithContext(ctx context.Context) (lastBlockHash *ChainLastBlockHashResponse, err error)
+}
+
+// TransactionService is the BitIndex transaction related requests
+type TransactionService interface {
+	GetTransaction(txID string) (transaction *Transaction, err error)
+	GetTransactionWithContext(ctx context.Context, txID string) (transaction *Transaction, err error)
+	GetTransactionRaw(txID string) (rawTx *TransactionRaw, err error)
+	GetTransactionRawWithContext(ctx context.Context, txID string) (rawTx *TransactionRaw, err error)
+	SendTransaction(rawTx string) (response *SendTransactionResponse, err error)
+	SendTransactionWithContext(ctx context.Context, rawTx string) (response *SendTransactionResponse, err error)
+}
+
+// WebhookService is the BitIndex webhook related requests
+type WebhookService interface {
+	AddMonitoredAddresses(addAddresses *MonitoredAddresses) (addresses MonitoredAddresses, err error)
+	AddMonitoredAddressesWithContext(ctx context.Context, addAddresses *MonitoredAddresses) (addresses MonitoredAddresses, err error)
+	GetMonitoredAddresses() (addresses MonitoredAddresses, err error)
+	GetMonitoredAddressesWithContext(ctx context.Context) (addresses MonitoredAddresses, err error)
+	GetWebhookConfig() (config *WebhookConfigResponse, err error)
+	GetWebhookConfigWithContext(ctx context.Context) (config *WebhookConfigResponse, err error)
+	UpdateWebhookConfig(updateConfig *WebhookUpdateConfig) (config *WebhookConfigResponse, err error)
+	UpdateWebhookConfigWithContext(ctx context.Context, updateConfig *WebhookUpdateConfig) (config *WebhookConfigResponse, err error)
+}
+
+// ClientInterface is the BitIndex client interface (satisfied by *Client)
+//
+// Depend on this (or one of the smaller service interfaces) to swap in test doubles,
+// alternate providers or decorators (caching, metrics, etc.)
+type ClientInterface interface {
+	AddressService
+	BlockService
+	ChainService
+	TransactionService
+	WebhookService
+	XpubService
+	Request(endpoint string, method string, payload []byte) (response string, err error)
+	RequestWithContext(ctx context.Context, endpoint string, method string, payload []byte) (response string, err error)
+}
+
+// Make sure the Client satisfies the ClientInterface
+var _ ClientInterface = (*Client)(nil)
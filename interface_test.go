@@ -0,0 +1,55 @@
+package bitindex
+
+import (
+	"context"
+	"testing"
+)
+
+// fakeAddressService is a test double that only overrides AddressInfoWithContext()
+type fakeAddressService struct {
+	AddressService
+}
+
+// AddressInfoWithContext returns a fixed address info
+func (f *fakeAddressService) AddressInfoWithContext(_ context.Context, address string) (*AddressInfo, error) {
+	return &AddressInfo{Address: address}, nil
+}
+
+// TestClientInterface tests that the client satisfies the service interfaces
+func TestClientInterface(t *testing.T) {
+	t.Parallel()
+
+	client, err := NewClient("test-key", NetworkMain, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var services = []interface{}{
+		AddressService(client),
+		BlockService(client),
+		ChainService(client),
+		ClientInterface(client),
+		TransactionService(client),
+		WebhookService(client),
+		XpubService(client),
+	}
+	for _, service := range services {
+		if service == nil {
+			t.Fatal("service should not be nil")
+		}
+	}
+}
+
+// TestAddressService_Fake tests swapping in a test double for the AddressService
+func TestAddressService_Fake(t *testing.T) {
+	t.Parallel()
+
+	var service AddressService = &fakeAddressService{}
+	info, err := service.AddressInfoWithContext(context.Background(), "16ZqP5Tb22KJuvSAbjNkoiZs13mmRmexZA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Address != "16ZqP5Tb22KJuvSAbjNkoiZs13mmRmexZA" {
+		t.Fatalf("unexpected address: %s", info.Address)
+	}
+}
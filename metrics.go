@@ -0,0 +1,229 @@
+package bitindex
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// DefaultLatencyBuckets are the default latency histogram buckets (in seconds)
+var DefaultLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// MetricsCollector is a RequestHook that collects Prometheus-style metrics per endpoint
+//
+// Counters: requests (by endpoint, method & status code), errors, retries and response bytes
+// Histogram: request latency (by endpoint)
+//
+// Use WritePrometheus() to expose the metrics in the Prometheus text format, or the getters
+// to read them (no network or Prometheus client required)
+type MetricsCollector struct {
+	buckets       []float64                    // are the latency histogram buckets (in seconds)
+	errors        map[string]uint64            // are the errors (no response) by endpoint
+	latency       map[string]*latencyHistogram // is the latency histogram by endpoint
+	mu            sync.Mutex                   // guards the metrics
+	requests      map[requestMetricKey]uint64  // are the responses by endpoint, method & status
+	responseBytes map[string]uint64            // are the response bytes by endpoint
+	retries       map[string]uint64            // are the retry attempts by endpoint
+}
+
+// requestMetricKey is the labels for the request counter
+type requestMetricKey struct {
+	method     string
+	name       string
+	statusCode int
+}
+
+// latencyHistogram is a cumulative latency histogram
+type latencyHistogram struct {
+	counts []uint64 // are the counts per bucket (cumulative when written)
+	count  uint64   // is the total number of observations
+	sum    float64  // is the sum of all observations (in seconds)
+}
+
+// NewMetricsCollector will create a new metrics collector (nil buckets uses DefaultLatencyBuckets)
+func NewMetricsCollector(buckets []float64) *MetricsCollector {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	return &MetricsCollector{
+		buckets:       buckets,
+		errors:        make(map[string]uint64),
+		latency:       make(map[string]*latencyHistogram),
+		requests:      make(map[requestMetricKey]uint64),
+		responseBytes: make(map[string]uint64),
+		retries:       make(map[string]uint64),
+	}
+}
+
+// BeforeRequest counts the retries
+func (m *MetricsCollector) BeforeRequest(_ context.Context, info *RequestInfo) {
+	if info.Attempt == 0 {
+		return
+	}
+	m.mu.Lock()
+	m.retries[info.Name]++
+	m.mu.Unlock()
+}
+
+// AfterResponse counts the request and observes the latency
+func (m *MetricsCollector) AfterResponse(_ context.Context, info *RequestInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[requestMetricKey{method: info.Method, name: info.Name, statusCode: info.StatusCode}]++
+	m.responseBytes[info.Name] += uint64(info.ResponseBytes)
+	m.observeLatency(info)
+}
+
+// OnError counts the error and observes the latency
+func (m *MetricsCollector) OnError(_ context.Context, info *RequestInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.errors[info.Name]++
+	m.observeLatency(info)
+}
+
+// observeLatency will add the latency to the endpoint histogram (lock must be held)
+func (m *MetricsCollector) observeLatency(info *RequestInfo) {
+	histogram, ok := m.latency[info.Name]
+	if !ok {
+		histogram = &latencyHistogram{counts: make([]uint64, len(m.buckets))}
+		m.latency[info.Name] = histogram
+	}
+
+	seconds := info.Latency.Seconds()
+	for i, bucket := range m.buckets {
+		if seconds <= bucket {
+			histogram.counts[i]++
+			break
+		}
+	}
+	histogram.count++
+	histogram.sum += seconds
+}
+
+// RequestCount returns the number of responses for the endpoint name with the status code
+func (m *MetricsCollector) RequestCount(name string, statusCode int) (count uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, value := range m.requests {
+		if key.name == name && key.statusCode == statusCode {
+			count += value
+		}
+	}
+	return
+}
+
+// ErrorCount returns the number of errors (no response) for the endpoint name
+func (m *MetricsCollector) ErrorCount(name string) uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.errors[name]
+}
+
+// RetryCount returns the number of retry attempts for the endpoint name
+func (m *MetricsCollector) RetryCount(name string) uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.retries[name]
+}
+
+// LatencyCount returns the number of latency observations for the endpoint name
+func (m *MetricsCollector) LatencyCount(name string) uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if histogram, ok := m.latency[name]; ok {
+		return histogram.count
+	}
+	return 0
+}
+
+// WritePrometheus writes the metrics in the Prometheus text exposition format
+func (m *MetricsCollector) WritePrometheus(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	buf := bufio.NewWriter(w)
+
+	// Requests by endpoint, method & status code
+	keys := make([]requestMetricKey, 0, len(m.requests))
+	for key := range m.requests {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].name != keys[j].name {
+			return keys[i].name < keys[j].name
+		} else if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+		return keys[i].statusCode < keys[j].statusCode
+	})
+	_, _ = fmt.Fprintln(buf, "# HELP bitindex_requests_total Total responses from the BitIndex api.")
+	_, _ = fmt.Fprintln(buf, "# TYPE bitindex_requests_total counter")
+	for _, key := range keys {
+		_, _ = fmt.Fprintf(buf, "bitindex_requests_total{endpoint=%q,method=%q,code=\"%d\"} %d\n",
+			key.name, key.method, key.statusCode, m.requests[key])
+	}
+
+	// Counters by endpoint
+	writeEndpointCounter(buf, "bitindex_request_errors_total", "Total requests that failed without a response.", m.errors)
+	writeEndpointCounter(buf, "bitindex_request_retries_total", "Total retry attempts.", m.retries)
+	writeEndpointCounter(buf, "bitindex_response_bytes_total", "Total bytes received in response bodies.", m.responseBytes)
+
+	// Latency histogram by endpoint
+	_, _ = fmt.Fprintln(buf, "# HELP bitindex_request_duration_seconds Latency of requests to the BitIndex api.")
+	_, _ = fmt.Fprintln(buf, "# TYPE bitindex_request_duration_seconds histogram")
+	for _, name := range sortedKeys(m.latency) {
+		histogram := m.latency[name]
+		var cumulative uint64
+		for i, bucket := range m.buckets {
+			cumulative += histogram.counts[i]
+			_, _ = fmt.Fprintf(buf, "bitindex_request_duration_seconds_bucket{endpoint=%q,le=%q} %d\n",
+				name, strconv.FormatFloat(bucket, 'g', -1, 64), cumulative)
+		}
+		_, _ = fmt.Fprintf(buf, "bitindex_request_duration_seconds_bucket{endpoint=%q,le=\"+Inf\"} %d\n", name, histogram.count)
+		_, _ = fmt.Fprintf(buf, "bitindex_request_duration_seconds_sum{endpoint=%q} %s\n",
+			name, strconv.FormatFloat(histogram.sum, 'g', -1, 64))
+		_, _ = fmt.Fprintf(buf, "bitindex_request_duration_seconds_count{endpoint=%q} %d\n", name, histogram.count)
+	}
+
+	return buf.Flush()
+}
+
+// writeEndpointCounter writes a counter by endpoint in the Prometheus text format
+func writeEndpointCounter(w io.Writer, metric, help string, values map[string]uint64) {
+	_, _ = fmt.Fprintf(w, "# HELP %s %s\n", metric, help)
+	_, _ = fmt.Fprintf(w, "# TYPE %s counter\n", metric)
+	for _, name := range sortedKeys(values) {
+		_, _ = fmt.Fprintf(w, "%s{endpoint=%q} %d\n", metric, name, values[name])
+	}
+}
+
+// sortedKeys returns the sorted keys of a map keyed by endpoint name
+func sortedKeys(values interface{}) (keys []string) {
+	switch v := values.(type) {
+	case map[string]uint64:
+		for key := range v {
+			keys = append(keys, key)
+		}
+	case map[string]*latencyHistogram:
+		for key := range v {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return
+}
+
+// Make sure the MetricsCollector is a RequestHook
+var _ RequestHook = (*MetricsCollector)(nil)
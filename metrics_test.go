@@ -0,0 +1,94 @@
+package bitindex
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMetricsCollector tests the MetricsCollector without a network
+func TestMetricsCollector(t *testing.T) {
+	t.Parallel()
+
+	metrics := NewMetricsCollector([]float64{0.5, 0.1})
+	ctx := context.Background()
+
+	metrics.BeforeRequest(ctx, &RequestInfo{Name: "AddressInfo", Method: http.MethodGet})
+	metrics.AfterResponse(ctx, &RequestInfo{
+		Latency: 50 * time.Millisecond, Method: http.MethodGet, Name: "AddressInfo",
+		ResponseBytes: 100, StatusCode: http.StatusServiceUnavailable,
+	})
+	metrics.BeforeRequest(ctx, &RequestInfo{Attempt: 1, Name: "AddressInfo", Method: http.MethodGet})
+	metrics.AfterResponse(ctx, &RequestInfo{
+		Latency: 200 * time.Millisecond, Method: http.MethodGet, Name: "AddressInfo",
+		ResponseBytes: 50, StatusCode: http.StatusOK,
+	})
+	metrics.OnError(ctx, &RequestInfo{
+		Error: errors.New("connection reset"), Latency: time.Second, Method: http.MethodPost, Name: "SendTransaction",
+	})
+
+	if count := metrics.RequestCount("AddressInfo", http.StatusOK); count != 1 {
+		t.Fatalf("expected 1 request, got: %d", count)
+	}
+	if count := metrics.RetryCount("AddressInfo"); count != 1 {
+		t.Fatalf("expected 1 retry, got: %d", count)
+	}
+	if count := metrics.ErrorCount("SendTransaction"); count != 1 {
+		t.Fatalf("expected 1 error, got: %d", count)
+	}
+	if count := metrics.LatencyCount("AddressInfo"); count != 2 {
+		t.Fatalf("expected 2 observations, got: %d", count)
+	}
+
+	var buf bytes.Buffer
+	if err := metrics.WritePrometheus(&buf); err != nil {
+		t.Fatal(err)
+	}
+	output := buf.String()
+
+	for _, line := range []string{
+		`bitindex_requests_total{endpoint="AddressInfo",method="GET",code="200"} 1`,
+		`bitindex_requests_total{endpoint="AddressInfo",method="GET",code="503"} 1`,
+		`bitindex_request_errors_total{endpoint="SendTransaction"} 1`,
+		`bitindex_request_retries_total{endpoint="AddressInfo"} 1`,
+		`bitindex_response_bytes_total{endpoint="AddressInfo"} 150`,
+		`bitindex_request_duration_seconds_bucket{endpoint="AddressInfo",le="0.1"} 1`,
+		`bitindex_request_duration_seconds_bucket{endpoint="AddressInfo",le="0.5"} 2`,
+		`bitindex_request_duration_seconds_bucket{endpoint="AddressInfo",le="+Inf"} 2`,
+		`bitindex_request_duration_seconds_bucket{endpoint="SendTransaction",le="0.5"} 0`,
+		`bitindex_request_duration_seconds_bucket{endpoint="SendTransaction",le="+Inf"} 1`,
+		`bitindex_request_duration_seconds_sum{endpoint="AddressInfo"} 0.25`,
+		`bitindex_request_duration_seconds_count{endpoint="AddressInfo"} 2`,
+	} {
+		if !strings.Contains(output, line+"\n") {
+			t.Fatalf("missing line: %s\n%s", line, output)
+		}
+	}
+}
+
+// TestClient_MetricsCollector tests the MetricsCollector as a client hook
+func TestClient_MetricsCollector(t *testing.T) {
+	t.Parallel()
+
+	metrics := NewMetricsCollector(nil)
+	options := ClientDefaultOptions()
+	options.RequestHooks = []RequestHook{metrics}
+
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"blockHash":"test"}`))
+	}), options)
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetBlockHashByHeight(int64(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if count := metrics.RequestCount("GetBlockHashByHeight", http.StatusOK); count != 3 {
+		t.Fatalf("expected 3 requests, got: %d", count)
+	}
+}
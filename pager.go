@@ -0,0 +1,62 @@
+package bitindex
+
+import "context"
+
+// pageFetcher gets the next page, returns the key of each item and true if it is the last page
+type pageFetcher func(ctx context.Context) (keys []string, last bool, err error)
+
+// pager walks the pages of a paged request one item at a time (shared by the iterators)
+//
+// Items already returned are skipped (pages can shift between requests) and the walk stops when a
+// page has no new items (IE: the server ignored the offset) instead of fetching the same page forever
+type pager struct {
+	ctx     context.Context     // is the context for the requests
+	done    bool                // is set when the last page was fetched
+	err     error               // is the first error
+	fetch   pageFetcher         // gets the next page
+	index   int                 // is the position in the current page
+	keys    []string            // are the keys of the current page
+	seen    map[string]struct{} // are the keys already returned
+	stalled bool                // is set until the current page has a new item
+}
+
+// newPager will create a pager for the fetcher
+func newPager(ctx context.Context, fetch pageFetcher) *pager {
+	return &pager{
+		ctx:   ctx,
+		fetch: fetch,
+		seen:  make(map[string]struct{}),
+	}
+}
+
+// next returns the index of the next new item in the current page, false when done or on error
+func (p *pager) next() (int, bool) {
+	for {
+		if p.err != nil {
+			return 0, false
+		} else if err := p.ctx.Err(); err != nil {
+			p.err = err
+			return 0, false
+		}
+
+		// Next item in the page (skip duplicates from shifted pages)
+		for p.index < len(p.keys) {
+			index := p.index
+			p.index++
+			if _, ok := p.seen[p.keys[index]]; ok {
+				continue
+			}
+			p.seen[p.keys[index]] = struct{}{}
+			p.stalled = false
+			return index, true
+		}
+
+		// A page without a new item will not move the walk forward
+		if p.done || p.stalled {
+			p.done = true
+			return 0, false
+		}
+		p.index, p.stalled = 0, true
+		p.keys, p.done, p.err = p.fetch(p.ctx)
+	}
+}
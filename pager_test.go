@@ -0,0 +1,37 @@
+package bitindex
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// ignoredOffsetService returns the same page for every offset (like a server that ignores the offset)
+type ignoredOffsetService struct {
+	XpubService
+	requests int
+}
+
+// GetXpubAddressesPageWithContext returns the first page of addresses
+func (s *ignoredOffsetService) GetXpubAddressesPageWithContext(_ context.Context, _ string, options *GetXpubAddressesOptions) (addresses XpubAddresses, err error) {
+	s.requests++
+	for i := 0; i < options.Limit; i++ {
+		addresses = append(addresses, XPubAddress{Address: fmt.Sprintf("1address%d", i), Num: i})
+	}
+	return
+}
+
+// TestXpubAddressIterator_IgnoredOffset tests stopping at a page without a new address
+func TestXpubAddressIterator_IgnoredOffset(t *testing.T) {
+	t.Parallel()
+
+	service := &ignoredOffsetService{}
+	iterator := NewXpubAddressIterator(context.Background(), service, "xpub-ignored-offset", &GetXpubAddressesOptions{Limit: 10})
+	count := 0
+	for iterator.Next() {
+		count++
+	}
+	if iterator.Err() != nil || count != 10 || service.requests != 2 {
+		t.Fatalf("expected 10 addresses in 2 requests got: %d %d %v", count, service.requests, iterator.Err())
+	}
+}
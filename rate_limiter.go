@@ -0,0 +1,123 @@
+package bitindex
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket shared by every request (and goroutine) of a client
+//
+// It also honors the Retry-After header: once the api asks us to back off, every request
+// waits until that time has passed (even if no rate limit is configured)
+type rateLimiter struct {
+	burst      float64    // is the maximum number of tokens in the bucket
+	last       time.Time  // is the last time tokens were added
+	mu         sync.Mutex // guards the bucket
+	pauseUntil time.Time  // is set from the Retry-After header
+	rate       float64    // is the number of tokens added per second (0 is unlimited)
+	tokens     float64    // is the current number of tokens
+}
+
+// newRateLimiter will create a new rate limiter (rate is requests per second, 0 is unlimited)
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		burst:  float64(burst),
+		last:   time.Now(),
+		rate:   rate,
+		tokens: float64(burst),
+	}
+}
+
+// Wait will block until a request is allowed or the context is done
+func (r *rateLimiter) Wait(ctx context.Context) error {
+	for {
+		delay := r.reserve(time.Now())
+		if delay <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve will take a token and return zero, or return how long to wait before trying again
+func (r *rateLimiter) reserve(now time.Time) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// The api asked us to back off
+	if now.Before(r.pauseUntil) {
+		return r.pauseUntil.Sub(now)
+	}
+
+	// No rate limit
+	if r.rate <= 0 {
+		return 0
+	}
+
+	// Refill the bucket
+	if elapsed := now.Sub(r.last).Seconds(); elapsed > 0 {
+		r.tokens += elapsed * r.rate
+		if r.tokens > r.burst {
+			r.tokens = r.burst
+		}
+	}
+	r.last = now
+
+	// Take a token
+	if r.tokens >= 1 {
+		r.tokens--
+		return 0
+	}
+
+	// Wait for the next token
+	return time.Duration((1 - r.tokens) / r.rate * float64(time.Second))
+}
+
+// Pause will stop all requests for the given duration
+func (r *rateLimiter) Pause(duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if until := time.Now().Add(duration); until.After(r.pauseUntil) {
+		r.pauseUntil = until
+	}
+}
+
+// parseRetryAfter will parse the Retry-After header (seconds or an HTTP date)
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value = strings.TrimSpace(value); len(value) == 0 {
+		return 0, false
+	}
+
+	// Delay in seconds
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	// HTTP date
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if delay := date.Sub(now); delay > 0 {
+		return delay, true
+	}
+	return 0, true
+}
@@ -0,0 +1,151 @@
+package bitindex
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestRateLimiter_Wait tests the token bucket
+func TestRateLimiter_Wait(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unlimited", func(t *testing.T) {
+		limiter := newRateLimiter(0, 0)
+		start := time.Now()
+		for i := 0; i < 100; i++ {
+			if err := limiter.Wait(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+			t.Fatalf("unlimited should not block, took: %v", elapsed)
+		}
+	})
+
+	t.Run("burst then rate", func(t *testing.T) {
+		limiter := newRateLimiter(50, 5)
+		start := time.Now()
+		for i := 0; i < 10; i++ {
+			if err := limiter.Wait(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// 5 from the burst, 5 more at 50/s = ~100ms
+		if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+			t.Fatalf("expected to be rate limited, took: %v", elapsed)
+		}
+	})
+
+	t.Run("shared across goroutines", func(t *testing.T) {
+		limiter := newRateLimiter(100, 1)
+		start := time.Now()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := limiter.Wait(context.Background()); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		// 1 from the burst, 9 more at 100/s = ~90ms
+		if elapsed := time.Since(start); elapsed < 70*time.Millisecond {
+			t.Fatalf("expected to be rate limited, took: %v", elapsed)
+		}
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		limiter := newRateLimiter(1, 1)
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		if err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected context.DeadlineExceeded, got: %v", err)
+		}
+	})
+
+	t.Run("pause", func(t *testing.T) {
+		limiter := newRateLimiter(0, 0)
+		limiter.Pause(50 * time.Millisecond)
+		start := time.Now()
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+			t.Fatalf("expected to be paused, took: %v", elapsed)
+		}
+	})
+}
+
+// TestParseRetryAfter tests the parseRetryAfter()
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var tests = []struct {
+		value         string
+		expectedDelay time.Duration
+		expectedOk    bool
+	}{
+		{"", 0, false},
+		{"5", 5 * time.Second, true},
+		{" 0 ", 0, true},
+		{"-1", 0, false},
+		{"Wed, 01 Jan 2020 00:00:30 GMT", 30 * time.Second, true},
+		{"Tue, 31 Dec 2019 23:59:30 GMT", 0, true},
+		{"soon", 0, false},
+	}
+
+	for _, test := range tests {
+		delay, ok := parseRetryAfter(test.value, now)
+		if delay != test.expectedDelay || ok != test.expectedOk {
+			t.Fatalf("%q: expected %v %t, got: %v %t", test.value, test.expectedDelay, test.expectedOk, delay, ok)
+		}
+	}
+}
+
+// TestClient_RateLimit tests the client honors the Retry-After header
+func TestClient_RateLimit(t *testing.T) {
+	t.Parallel()
+
+	// No retries (only testing the pause)
+	options := ClientDefaultOptions()
+	options.RequestRetryCount = 0
+
+	var requests int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Retry-After", "5")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}), options)
+
+	// First request is rate limited by the api
+	if _, err := client.AddressInfo("test"); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited, got: %v", err)
+	}
+
+	// Next request waits (instead of firing) until the context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.AddressInfoWithContext(ctx, "test"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got: %v", err)
+	}
+
+	if count := atomic.LoadInt32(&requests); count != 1 {
+		t.Fatalf("expected 1 request, got: %d", count)
+	}
+}
@@ -0,0 +1,297 @@
+package bitindex
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// blockHeaderSize is the size of a serialized block header
+const blockHeaderSize = 80
+
+var (
+	// ErrInvalidBlock is returned when a raw block can not be decoded
+	ErrInvalidBlock = errors.New("invalid block")
+
+	// ErrMerkleRootMismatch is returned when the transactions do not match the merkle root of the header
+	ErrMerkleRootMismatch = errors.New("merkle root mismatch")
+)
+
+// RawBlockHeader is a decoded block header
+type RawBlockHeader struct {
+	Bits              uint32 // is the encoded target
+	Hash              string // is the block hash (hex, displayed order)
+	MerkleRoot        string // is the merkle root of the transactions (hex, displayed order)
+	Nonce             uint32 // is the nonce
+	PreviousBlockHash string // is the hash of the previous block (hex, displayed order)
+	Time              uint32 // is the block time (unix)
+	Version           int32  // is the block version
+}
+
+// RawBlock is a decoded block (see BlockReader to stream the transactions instead)
+type RawBlock struct {
+	Header       RawBlockHeader    // is the header
+	Transactions []*RawTransaction // are the transactions
+}
+
+// DecodeRawBlock will decode the raw block hex and check the merkle root (IE: BlockRawResponse.RawBlock)
+//
+// Every transaction is kept in memory, use NewBlockReader() for large blocks
+func DecodeRawBlock(rawBlock string) (*RawBlock, error) {
+	reader, err := NewBlockReader(hex.NewDecoder(strings.NewReader(strings.TrimSpace(rawBlock))))
+	if err != nil {
+		return nil, err
+	}
+	block := &RawBlock{Header: *reader.Header()}
+	for reader.Next() {
+		block.Transactions = append(block.Transactions, reader.Transaction())
+	}
+	if err = reader.Err(); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// Reader returns a BlockReader that streams the transactions of the raw block (decoding the hex as it reads)
+//
+// The hex is already in memory, use GetBlockRawReader() to stream the block from the api response
+func (b *BlockRawResponse) Reader() (*BlockReader, error) {
+	return NewBlockReader(hex.NewDecoder(strings.NewReader(strings.TrimSpace(b.RawBlock))))
+}
+
+// BlockReader streams the transactions of a serialized block, one transaction at a time
+//
+// The merkle root is computed as the transactions are read (only O(log n) hashes are kept) and
+// checked against the header after the last transaction
+//
+//	reader, err := bitindex.NewBlockReader(file)
+//	for reader.Next() {
+//		tx := reader.Transaction()
+//	}
+//	if err = reader.Err(); err != nil {
+//		...
+//	}
+type BlockReader struct {
+	closer  io.Closer       // is the source to close (IE: the response body of GetBlockRawReader)
+	count   uint64          // is the number of transactions in the block
+	current *RawTransaction // is the current transaction
+	err     error           // is the first error
+	header  RawBlockHeader  // is the block header
+	merkle  merkleBuilder   // is the merkle root so far
+	read    uint64          // is the number of transactions read
+	reader  *bufio.Reader   // is the source
+}
+
+// NewBlockReader will read the block header and the transaction count from the serialized block
+func NewBlockReader(reader io.Reader) (*BlockReader, error) {
+	b := &BlockReader{reader: bufio.NewReaderSize(reader, 64*1024)}
+
+	// Header
+	header := make([]byte, blockHeaderSize)
+	if _, err := io.ReadFull(b.reader, header); err != nil {
+		return nil, fmt.Errorf("%w: header: %s", ErrInvalidBlock, err.Error())
+	}
+	b.header = decodeBlockHeader(header)
+
+	// Transaction count
+	d := newRawDecoder(b.reader, nil)
+	if b.count = d.varInt(); d.err != nil {
+		return nil, fmt.Errorf("%w: transaction count: %s", ErrInvalidBlock, d.err.Error())
+	} else if b.count == 0 {
+		return nil, fmt.Errorf("%w: no transactions", ErrInvalidBlock)
+	}
+	return b, nil
+}
+
+// Header returns the block header
+func (b *BlockReader) Header() *RawBlockHeader {
+	return &b.header
+}
+
+// TransactionCount returns the number of transactions in the block
+func (b *BlockReader) TransactionCount() uint64 {
+	return b.count
+}
+
+// Next will read the next transaction, returns false when done or on error (see Err())
+func (b *BlockReader) Next() bool {
+	b.current = nil
+	if b.err != nil || b.read == b.count {
+		return false
+	}
+
+	tx, err := ReadRawTransaction(b.reader)
+	if err != nil {
+		b.err = fmt.Errorf("%w: transaction %d: %s", ErrInvalidBlock, b.read, err.Error())
+		return false
+	}
+	b.read++
+	b.current = tx
+
+	txID, _ := hex.DecodeString(tx.TxID)
+	b.merkle.add(reverseBytes(txID))
+
+	// Last transaction (check the merkle root and the end of the block)
+	if b.read == b.count {
+		if root := hex.EncodeToString(reverseBytes(b.merkle.root())); root != b.header.MerkleRoot {
+			b.err = fmt.Errorf("%w: computed %s and the header has %s", ErrMerkleRootMismatch, root, b.header.MerkleRoot)
+		} else if _, err = b.reader.ReadByte(); err != io.EOF {
+			b.err = fmt.Errorf("%w: unexpected data after the last transaction", ErrInvalidBlock)
+		}
+	}
+	return true
+}
+
+// Transaction returns the current transaction
+func (b *BlockReader) Transaction() *RawTransaction {
+	return b.current
+}
+
+// Err returns the error that stopped the reader (or the merkle root mismatch after the last transaction)
+func (b *BlockReader) Err() error {
+	return b.err
+}
+
+// Close will close the source of the reader (IE: the response body of GetBlockRawReader)
+func (b *BlockReader) Close() error {
+	if b.closer == nil {
+		return nil
+	}
+	return b.closer.Close()
+}
+
+// rawBlockField returns a reader of the hex of the "rawblock" field in the JSON body (read from the body as needed)
+//
+// The fields before it are skipped with a json.Decoder, then the string is read from the rest of the body
+func rawBlockField(body io.Reader) (io.Reader, error) {
+	decoder := json.NewDecoder(body)
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, fmt.Errorf("%w: expected a JSON object", ErrInvalidBlock)
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidBlock, err.Error())
+		}
+		if key, _ := token.(string); key != "rawblock" {
+			var value json.RawMessage
+			if err = decoder.Decode(&value); err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidBlock, err.Error())
+			}
+			continue
+		}
+
+		// The value (after the colon) is in the rest of the body
+		value := bufio.NewReader(io.MultiReader(decoder.Buffered(), body))
+		for {
+			c, err := value.ReadByte()
+			if err != nil {
+				return nil, fmt.Errorf("%w: rawblock: %s", ErrInvalidBlock, err.Error())
+			}
+			switch c {
+			case ' ', '\t', '\n', '\r', ':':
+				continue
+			case '"':
+				return &jsonStringReader{reader: value}, nil
+			}
+			return nil, fmt.Errorf("%w: rawblock is not a string", ErrInvalidBlock)
+		}
+	}
+	return nil, fmt.Errorf("%w: missing rawblock", ErrInvalidBlock)
+}
+
+// jsonStringReader reads a JSON string without escapes (IE: hex) up to the closing quote
+type jsonStringReader struct {
+	done   bool          // is set after the closing quote
+	reader *bufio.Reader // is the source (after the opening quote)
+}
+
+// Read will read the string until the closing quote (then io.EOF)
+//
+// Only blocks for the first byte, so the buffered part of the body is returned while the rest arrives
+func (j *jsonStringReader) Read(p []byte) (n int, err error) {
+	for n < len(p) && !j.done && (n == 0 || j.reader.Buffered() > 0) {
+		var c byte
+		if c, err = j.reader.ReadByte(); err == io.EOF {
+			return n, io.ErrUnexpectedEOF
+		} else if err != nil {
+			return n, err
+		}
+		switch c {
+		case '"':
+			j.done = true
+		case '\\':
+			return n, fmt.Errorf("%w: escaped character in rawblock", ErrInvalidBlock)
+		default:
+			p[n] = c
+			n++
+		}
+	}
+	if n == 0 && j.done {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+// decodeBlockHeader decodes the 80 byte header
+func decodeBlockHeader(header []byte) RawBlockHeader {
+	return RawBlockHeader{
+		Bits:              binary.LittleEndian.Uint32(header[72:76]),
+		Hash:              hex.EncodeToString(reverseBytes(doubleSha256(header))),
+		MerkleRoot:        hex.EncodeToString(reverseBytes(header[36:68])),
+		Nonce:             binary.LittleEndian.Uint32(header[76:80]),
+		PreviousBlockHash: hex.EncodeToString(reverseBytes(header[4:36])),
+		Time:              binary.LittleEndian.Uint32(header[68:72]),
+		Version:           int32(binary.LittleEndian.Uint32(header[0:4])),
+	}
+}
+
+// merkleBuilder computes a merkle root from the leaves as they are added
+//
+// Pending subtrees are kept on a stack (one per level), an odd node is paired with itself
+type merkleBuilder struct {
+	stack []merkleNode // are the pending subtrees (levels decrease to the top)
+}
+
+// merkleNode is a pending subtree
+type merkleNode struct {
+	hash  []byte // is the subtree hash
+	level int    // is the height of the subtree
+}
+
+// add will add a leaf (merging the complete subtrees)
+func (m *merkleBuilder) add(leaf []byte) {
+	node := merkleNode{hash: leaf}
+	for len(m.stack) > 0 && m.stack[len(m.stack)-1].level == node.level {
+		top := m.stack[len(m.stack)-1]
+		m.stack = m.stack[:len(m.stack)-1]
+		node = merkleNode{hash: merkleParent(top.hash, node.hash), level: node.level + 1}
+	}
+	m.stack = append(m.stack, node)
+}
+
+// root returns the merkle root (the last node of every odd level is duplicated)
+func (m *merkleBuilder) root() []byte {
+	if len(m.stack) == 0 {
+		return nil
+	}
+	node := m.stack[len(m.stack)-1]
+	for i := len(m.stack) - 2; i >= 0; i-- {
+		for node.level < m.stack[i].level {
+			node = merkleNode{hash: merkleParent(node.hash, node.hash), level: node.level + 1}
+		}
+		node = merkleNode{hash: merkleParent(m.stack[i].hash, node.hash), level: node.level + 1}
+	}
+	return node.hash
+}
+
+// merkleParent returns the double sha256 of the two child hashes
+func merkleParent(left, right []byte) []byte {
+	return doubleSha256(bytes.Join([][]byte{left, right}, nil))
+}
@@ -0,0 +1,235 @@
+package bitindex
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// The genesis block (header and coinbase transaction)
+const (
+	testGenesisBlock     = "0100000000000000000000000000000000000000000000000000000000000000000000003ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a29ab5f49ffff001d1dac2b7c01" + testGenesisTx
+	testGenesisBlockHash = "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"
+)
+
+// naiveMerkleRoot computes the merkle root level by level (to check the streaming builder)
+func naiveMerkleRoot(leaves [][]byte) []byte {
+	level := leaves
+	for len(level) > 1 {
+		if len(level)%2 == 1 {
+			level = append(level, level[len(level)-1])
+		}
+		var next [][]byte
+		for i := 0; i < len(level); i += 2 {
+			next = append(next, merkleParent(level[i], level[i+1]))
+		}
+		level = next
+	}
+	return level[0]
+}
+
+// testBlock returns a serialized block with count transactions (and the merkle root of the header)
+func testBlock(count int, merkleRoot []byte) (block []byte, leaves [][]byte) {
+	var transactions []byte
+	for i := 0; i < count; i++ {
+		tx := (&RawTransaction{
+			Inputs:   []RawInput{{PreviousTxID: testGenesisTxID, ScriptSig: []byte{byte(i)}}},
+			LockTime: uint32(i),
+			Outputs:  []RawOutput{{LockingScript: []byte{opReturn}, Value: Satoshis(i)}},
+			Version:  1,
+		}).Bytes()
+		transactions = append(transactions, tx...)
+		leaves = append(leaves, doubleSha256(tx))
+	}
+	if merkleRoot == nil {
+		merkleRoot = naiveMerkleRoot(leaves)
+	}
+
+	header := make([]byte, blockHeaderSize)
+	binary.LittleEndian.PutUint32(header[0:4], 2)
+	copy(header[36:68], merkleRoot)
+	binary.LittleEndian.PutUint32(header[68:72], 1600000000)
+
+	var buf bytes.Buffer
+	buf.Write(header)
+	writeVarInt(&buf, uint64(count))
+	buf.Write(transactions)
+	return buf.Bytes(), leaves
+}
+
+// TestDecodeRawBlock tests decoding the genesis block
+func TestDecodeRawBlock(t *testing.T) {
+	t.Parallel()
+
+	block, err := DecodeRawBlock(testGenesisBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := block.Header
+	if header.Hash != testGenesisBlockHash || header.MerkleRoot != testGenesisTxID || header.Version != 1 {
+		t.Fatalf("unexpected header: %+v", header)
+	}
+	if header.Time != 1231006505 || header.Bits != 0x1d00ffff || header.Nonce != 2083236893 || header.PreviousBlockHash != coinbaseTxID {
+		t.Fatalf("unexpected header: %+v", header)
+	}
+	if len(block.Transactions) != 1 || block.Transactions[0].TxID != testGenesisTxID {
+		t.Fatalf("unexpected transactions: %+v", block.Transactions)
+	}
+}
+
+// TestBlockReader tests streaming blocks and the merkle root for every tree shape up to 17 transactions
+func TestBlockReader(t *testing.T) {
+	t.Parallel()
+
+	for count := 1; count <= 17; count++ {
+		block, leaves := testBlock(count, nil)
+
+		reader, err := (&BlockRawResponse{RawBlock: hex.EncodeToString(block)}).Reader()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reader.TransactionCount() != uint64(count) || reader.Header().Version != 2 {
+			t.Fatalf("%d: unexpected header: %+v", count, reader.Header())
+		}
+
+		read := 0
+		for reader.Next() {
+			if reader.Transaction().LockTime != uint32(read) {
+				t.Fatalf("%d: unexpected transaction: %+v", count, reader.Transaction())
+			}
+			read++
+		}
+		if err = reader.Err(); err != nil {
+			t.Fatalf("%d: %v", count, err)
+		}
+		if read != count || reader.Transaction() != nil {
+			t.Fatalf("%d: expected %d transactions got: %d", count, count, read)
+		}
+		if reader.Header().MerkleRoot != hex.EncodeToString(reverseBytes(naiveMerkleRoot(leaves))) {
+			t.Fatalf("%d: unexpected merkle root", count)
+		}
+	}
+}
+
+// TestBlockReader_Errors tests invalid blocks
+func TestBlockReader_Errors(t *testing.T) {
+	t.Parallel()
+
+	// Merkle root mismatch (the transactions are still returned)
+	block, _ := testBlock(3, make([]byte, 32))
+	reader, err := NewBlockReader(bytes.NewReader(block))
+	if err != nil {
+		t.Fatal(err)
+	}
+	read := 0
+	for reader.Next() {
+		read++
+	}
+	if read != 3 || !errors.Is(reader.Err(), ErrMerkleRootMismatch) {
+		t.Fatalf("expected ErrMerkleRootMismatch after 3 transactions got: %d %v", read, reader.Err())
+	}
+	if _, err = DecodeRawBlock(hex.EncodeToString(block)); !errors.Is(err, ErrMerkleRootMismatch) {
+		t.Fatalf("expected ErrMerkleRootMismatch got: %v", err)
+	}
+
+	// Truncated & trailing data
+	block, _ = testBlock(3, nil)
+	var tests = []string{
+		"",
+		"zz",
+		hex.EncodeToString(block[:blockHeaderSize-1]),
+		hex.EncodeToString(block[:blockHeaderSize]),
+		hex.EncodeToString(append(block[:blockHeaderSize:blockHeaderSize], 0x00)),
+		hex.EncodeToString(block[:len(block)-1]),
+		hex.EncodeToString(block) + "00",
+	}
+	for _, test := range tests {
+		if _, err = DecodeRawBlock(test); !errors.Is(err, ErrInvalidBlock) {
+			t.Fatalf("%d bytes: expected ErrInvalidBlock got: %v", len(test)/2, err)
+		}
+	}
+}
+
+// TestClient_GetBlockRawReader tests streaming the rawblock field of the response body
+func TestClient_GetBlockRawReader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("streams the block", func(t *testing.T) {
+		block, _ := testBlock(20, nil)
+		body := `{"hash":"abc","tx":["a","b"],"nested":{"rawblock":1},"rawblock": "` + hex.EncodeToString(block) + `"}`
+		split := len(body) - 2*len(block) + 2*(blockHeaderSize+1) - 2
+
+		// The rest of the block is sent after the header was read
+		release := make(chan struct{})
+		client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/v3/main/rawblock/abc" {
+				t.Errorf("unexpected path: %s", r.URL.Path)
+			}
+			_, _ = w.Write([]byte(body[:split]))
+			w.(http.Flusher).Flush()
+			select {
+			case <-release:
+			case <-time.After(5 * time.Second):
+			}
+			_, _ = w.Write([]byte(body[split:]))
+		}), nil)
+
+		result := make(chan error, 1)
+		var reader *BlockReader
+		go func() {
+			var err error
+			reader, err = client.GetBlockRawReader("abc")
+			result <- err
+		}()
+		select {
+		case err := <-result:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatal("expected the header before the rest of the body")
+		}
+		close(release)
+
+		read := 0
+		for reader.Next() {
+			read++
+		}
+		if read != 20 || reader.Err() != nil || reader.TransactionCount() != 20 {
+			t.Fatalf("expected 20 transactions got: %d %v", read, reader.Err())
+		}
+		if err := reader.Close(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		block, _ := testBlock(2, nil)
+		var tests = []struct {
+			body     string
+			expected error
+			status   int
+		}{
+			{`{"message":"not found"}`, ErrNotFound, http.StatusNotFound},
+			{`{"hash":"abc"}`, ErrInvalidBlock, http.StatusOK},
+			{`{"rawblock":12}`, ErrInvalidBlock, http.StatusOK},
+			{`{"rawblock":"` + hex.EncodeToString(block[:blockHeaderSize]) + `\u0030"}`, ErrInvalidBlock, http.StatusOK},
+			{`["rawblock"]`, ErrInvalidBlock, http.StatusOK},
+			{`{"rawblock":"` + hex.EncodeToString(block[:10]), ErrInvalidBlock, http.StatusOK},
+		}
+		for _, test := range tests {
+			test := test
+			client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.status)
+				_, _ = w.Write([]byte(test.body))
+			}), nil)
+			if _, err := client.GetBlockRawReader("abc"); !errors.Is(err, test.expected) {
+				t.Fatalf("%s: expected %v got: %v", test.body, test.expected, err)
+			}
+		}
+	})
+}
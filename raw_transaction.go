@@ -0,0 +1,357 @@
+package bitindex
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+// Script types (as the api returns them in scriptPubKey.type)
+const (
+	scriptTypeNonStandard = "nonstandard"
+	scriptTypePubKey      = "pubkey"
+	scriptTypePubKeyHash  = "pubkeyhash"
+	scriptTypeScriptHash  = "scripthash"
+)
+
+// coinbaseVout is the previous output index of a coinbase input
+const coinbaseVout = 0xffffffff
+
+var (
+	// ErrInvalidTransaction is returned when a raw transaction can not be decoded
+	ErrInvalidTransaction = errors.New("invalid transaction")
+
+	// ErrTransactionMismatch is returned when a transaction does not match the raw transaction
+	ErrTransactionMismatch = errors.New("transaction does not match the raw transaction")
+)
+
+// RawTransaction is a decoded (serialized) transaction
+type RawTransaction struct {
+	Inputs   []RawInput  // are the inputs
+	LockTime uint32      // is the lock time
+	Outputs  []RawOutput // are the outputs
+	Size     int         // is the size in bytes
+	TxID     string      // is the txid (hex, displayed order)
+	Version  int32       // is the transaction version
+}
+
+// RawInput is a decoded input
+type RawInput struct {
+	PreviousTxID string // is the txid of the output being spent (hex, displayed order)
+	PreviousVout uint32 // is the index of the output being spent
+	ScriptSig    []byte // is the unlocking script (or the coinbase data)
+	Sequence     uint32 // is the sequence number
+}
+
+// RawOutput is a decoded output
+type RawOutput struct {
+	LockingScript []byte   // is the locking script (scriptPubKey)
+	Value         Satoshis // is the amount
+}
+
+// DecodeRawTransaction will decode the raw transaction hex (IE: TransactionRaw.RawTx)
+func DecodeRawTransaction(rawTx string) (*RawTransaction, error) {
+	data, err := hex.DecodeString(strings.TrimSpace(rawTx))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTransaction, err.Error())
+	}
+	reader := bytes.NewReader(data)
+	tx, err := ReadRawTransaction(reader)
+	if err != nil {
+		return nil, err
+	} else if reader.Len() > 0 {
+		return nil, fmt.Errorf("%w: %d unexpected bytes after the transaction", ErrInvalidTransaction, reader.Len())
+	}
+	return tx, nil
+}
+
+// ReadRawTransaction will read one serialized transaction from the reader
+func ReadRawTransaction(reader io.Reader) (*RawTransaction, error) {
+	d := newRawDecoder(reader, sha256.New())
+	tx := &RawTransaction{Version: int32(d.uint32())}
+
+	// Inputs
+	count := d.varInt()
+	for i := uint64(0); i < count && d.err == nil; i++ {
+		input := RawInput{PreviousTxID: hex.EncodeToString(reverseBytes(d.bytes(32)))}
+		input.PreviousVout = d.uint32()
+		input.ScriptSig = d.bytes(d.varInt())
+		input.Sequence = d.uint32()
+		tx.Inputs = append(tx.Inputs, input)
+	}
+
+	// Outputs
+	count = d.varInt()
+	for i := uint64(0); i < count && d.err == nil; i++ {
+		output := RawOutput{Value: Satoshis(d.uint64())}
+		output.LockingScript = d.bytes(d.varInt())
+		tx.Outputs = append(tx.Outputs, output)
+	}
+
+	tx.LockTime = d.uint32()
+	if d.err != nil {
+		return nil, d.err
+	}
+	tx.Size = d.size
+	tx.TxID = hex.EncodeToString(reverseBytes(sha256Sum(d.hash.Sum(nil))))
+	return tx, nil
+}
+
+// Bytes returns the serialized transaction
+func (t *RawTransaction) Bytes() []byte {
+	var buf bytes.Buffer
+	writeUint32(&buf, uint32(t.Version))
+	writeVarInt(&buf, uint64(len(t.Inputs)))
+	for _, input := range t.Inputs {
+		previous, _ := hex.DecodeString(input.PreviousTxID)
+		buf.Write(reverseBytes(previous))
+		writeUint32(&buf, input.PreviousVout)
+		writeVarInt(&buf, uint64(len(input.ScriptSig)))
+		buf.Write(input.ScriptSig)
+		writeUint32(&buf, input.Sequence)
+	}
+	writeVarInt(&buf, uint64(len(t.Outputs)))
+	for _, output := range t.Outputs {
+		var value [8]byte
+		binary.LittleEndian.PutUint64(value[:], uint64(output.Value))
+		buf.Write(value[:])
+		writeVarInt(&buf, uint64(len(output.LockingScript)))
+		buf.Write(output.LockingScript)
+	}
+	writeUint32(&buf, t.LockTime)
+	return buf.Bytes()
+}
+
+// IsCoinbase returns true if the input creates new coins (no previous output)
+func (i *RawInput) IsCoinbase() bool {
+	return i.PreviousTxID == coinbaseTxID && i.PreviousVout == coinbaseVout
+}
+
+// ScriptPubKey returns the script pubkey (type & addresses) of the output for the network
+//
+// Only P2PKH, P2SH, P2PK and data (OP_RETURN) scripts are recognized, P2PK has no address
+func (o *RawOutput) ScriptPubKey(network NetworkType) ScriptPubKey {
+	script := o.LockingScript
+	scriptPubKey := ScriptPubKey{Hex: hex.EncodeToString(script), Type: scriptTypeNonStandard}
+	switch {
+	case len(script) == 25 && script[0] == 0x76 && script[1] == 0xa9 && script[2] == 0x14 && script[23] == 0x88 && script[24] == 0xac:
+		scriptPubKey.Type, scriptPubKey.RequiredSignatures = scriptTypePubKeyHash, 1
+		scriptPubKey.Addresses = []string{hash160Address(script[3:23], network, false)}
+	case len(script) == 23 && script[0] == 0xa9 && script[1] == 0x14 && script[22] == 0x87:
+		scriptPubKey.Type, scriptPubKey.RequiredSignatures = scriptTypeScriptHash, 1
+		scriptPubKey.Addresses = []string{hash160Address(script[2:22], network, true)}
+	case (len(script) == 35 || len(script) == 67) && int(script[0]) == len(script)-2 && script[len(script)-1] == 0xac:
+		scriptPubKey.Type, scriptPubKey.RequiredSignatures = scriptTypePubKey, 1
+	case len(script) > 0 && (script[0] == opReturn || (len(script) > 1 && script[0] == opFalse && script[1] == opReturn)):
+		scriptPubKey.Type = scriptTypeNullData
+	}
+	return scriptPubKey
+}
+
+// ToTransaction maps the raw transaction onto a Transaction (the fields the raw bytes have)
+//
+// Block, input value & input address fields are not part of the raw transaction and are not set
+func (t *RawTransaction) ToTransaction(network NetworkType) *Transaction {
+	tx := &Transaction{
+		Hash:     t.TxID,
+		LockTime: int64(t.LockTime),
+		RawTx:    hex.EncodeToString(t.Bytes()),
+		Size:     int64(t.Size),
+		TxID:     t.TxID,
+		Version:  int(t.Version),
+	}
+	for n, input := range t.Inputs {
+		vin := TransactionInput{
+			N:         n,
+			ScriptSig: ScriptSig{Hex: hex.EncodeToString(input.ScriptSig)},
+			Sequence:  int64(input.Sequence),
+		}
+		if !input.IsCoinbase() {
+			vin.TxID, vin.Vout = input.PreviousTxID, int(input.PreviousVout)
+		}
+		tx.Vin = append(tx.Vin, vin)
+	}
+	for n, output := range t.Outputs {
+		tx.Vout = append(tx.Vout, TransactionOutput{
+			N:             n,
+			ScriptPubKey:  output.ScriptPubKey(network),
+			Value:         output.Value,
+			ValueSatoshis: output.Value,
+		})
+		tx.ValueOut = tx.ValueOut.Add(output.Value)
+	}
+	return tx
+}
+
+// Verify will return ErrTransactionMismatch if the api transaction does not match the raw transaction
+//
+// Compares the txid, version, lock time, the outpoints, scripts & sequences of the inputs and the
+// values & scripts of the outputs (fields missing from the api transaction are skipped)
+func (t *RawTransaction) Verify(tx *Transaction) error {
+	mismatch := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: "+format, append([]interface{}{ErrTransactionMismatch}, args...)...)
+	}
+
+	if !strings.EqualFold(tx.TxID, t.TxID) {
+		return mismatch("txid %s is not %s", tx.TxID, t.TxID)
+	} else if tx.Version != int(t.Version) || tx.LockTime != int64(t.LockTime) {
+		return mismatch("version %d / lock time %d is not %d / %d", tx.Version, tx.LockTime, t.Version, t.LockTime)
+	} else if len(tx.Vin) != len(t.Inputs) || len(tx.Vout) != len(t.Outputs) {
+		return mismatch("%d inputs / %d outputs is not %d / %d", len(tx.Vin), len(tx.Vout), len(t.Inputs), len(t.Outputs))
+	}
+
+	for i, input := range t.Inputs {
+		vin := &tx.Vin[i]
+		if input.IsCoinbase() != vin.IsCoinbase() {
+			return mismatch("input %d coinbase mismatch", i)
+		} else if !input.IsCoinbase() && (!strings.EqualFold(vin.TxID, input.PreviousTxID) || vin.Vout != int(input.PreviousVout)) {
+			return mismatch("input %d spends %s:%d not %s:%d", i, vin.TxID, vin.Vout, input.PreviousTxID, input.PreviousVout)
+		} else if vin.Sequence != int64(input.Sequence) {
+			return mismatch("input %d sequence %d is not %d", i, vin.Sequence, input.Sequence)
+		} else if len(vin.ScriptSig.Hex) > 0 && !strings.EqualFold(vin.ScriptSig.Hex, hex.EncodeToString(input.ScriptSig)) {
+			return mismatch("input %d script is different", i)
+		}
+	}
+
+	for i, output := range t.Outputs {
+		vout := &tx.Vout[i]
+		value := vout.Value
+		if value == 0 {
+			value = vout.ValueSatoshis
+		}
+		if value != output.Value {
+			return mismatch("output %d value %s is not %s", i, value, output.Value)
+		} else if len(vout.ScriptPubKey.Hex) > 0 && !strings.EqualFold(vout.ScriptPubKey.Hex, hex.EncodeToString(output.LockingScript)) {
+			return mismatch("output %d script is different", i)
+		}
+	}
+	return nil
+}
+
+// VerifyRawTx will decode the RawTx of the transaction and Verify() the transaction against it
+func (t *Transaction) VerifyRawTx() error {
+	raw, err := DecodeRawTransaction(t.RawTx)
+	if err != nil {
+		return err
+	}
+	return raw.Verify(t)
+}
+
+// rawDecoder reads the little endian fields of the serialization (the first error sticks)
+type rawDecoder struct {
+	buf    [8]byte   // is the buffer for the fixed size fields
+	err    error     // is the first error
+	hash   hash.Hash // is the hash of everything read (optional)
+	reader io.Reader // is the source
+	size   int       // is the number of bytes read
+}
+
+// newRawDecoder creates a decoder (everything read is also written to the hash if set)
+func newRawDecoder(reader io.Reader, hash hash.Hash) *rawDecoder {
+	d := &rawDecoder{hash: hash, reader: reader}
+	if hash != nil {
+		d.reader = io.TeeReader(reader, hash)
+	}
+	return d
+}
+
+// fixed reads n (up to 8) bytes
+func (d *rawDecoder) fixed(n int) []byte {
+	if d.err != nil {
+		return d.buf[:n]
+	}
+	read, err := io.ReadFull(d.reader, d.buf[:n])
+	d.size += read
+	if err != nil {
+		d.fail(err)
+	}
+	return d.buf[:n]
+}
+
+// uint32 reads a little endian uint32
+func (d *rawDecoder) uint32() uint32 {
+	return binary.LittleEndian.Uint32(d.fixed(4))
+}
+
+// uint64 reads a little endian uint64
+func (d *rawDecoder) uint64() uint64 {
+	return binary.LittleEndian.Uint64(d.fixed(8))
+}
+
+// varInt reads a variable length integer (1, 3, 5 or 9 bytes)
+func (d *rawDecoder) varInt() uint64 {
+	switch prefix := d.fixed(1)[0]; prefix {
+	case 0xfd:
+		return uint64(binary.LittleEndian.Uint16(d.fixed(2)))
+	case 0xfe:
+		return uint64(d.uint32())
+	case 0xff:
+		return d.uint64()
+	default:
+		return uint64(prefix)
+	}
+}
+
+// bytes reads n bytes (the buffer grows as the data arrives, a bad length can not over-allocate)
+func (d *rawDecoder) bytes(n uint64) []byte {
+	if d.err != nil {
+		return nil
+	} else if n > 1<<62 {
+		d.fail(io.ErrUnexpectedEOF)
+		return nil
+	}
+	var buf bytes.Buffer
+	read, err := io.CopyN(&buf, d.reader, int64(n))
+	d.size += int(read)
+	if err != nil {
+		d.fail(err)
+		return nil
+	}
+	return buf.Bytes()
+}
+
+// fail sets the error (an early end of the data is an unexpected EOF)
+func (d *rawDecoder) fail(err error) {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	d.err = fmt.Errorf("%w: %s at byte %d", ErrInvalidTransaction, err.Error(), d.size)
+}
+
+// writeUint32 writes a little endian uint32
+func writeUint32(buf *bytes.Buffer, value uint32) {
+	var data [4]byte
+	binary.LittleEndian.PutUint32(data[:], value)
+	buf.Write(data[:])
+}
+
+// writeVarInt writes a variable length integer
+func writeVarInt(buf *bytes.Buffer, value uint64) {
+	switch {
+	case value < 0xfd:
+		buf.WriteByte(byte(value))
+	case value <= 0xffff:
+		buf.WriteByte(0xfd)
+		buf.Write([]byte{byte(value), byte(value >> 8)})
+	case value <= 0xffffffff:
+		buf.WriteByte(0xfe)
+		writeUint32(buf, uint32(value))
+	default:
+		var data [8]byte
+		binary.LittleEndian.PutUint64(data[:], value)
+		buf.WriteByte(0xff)
+		buf.Write(data[:])
+	}
+}
+
+// sha256Sum returns the sha256 of the data
+func sha256Sum(data []byte) []byte {
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
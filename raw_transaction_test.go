@@ -0,0 +1,154 @@
+package bitindex
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// The coinbase transaction of the genesis block
+const (
+	testGenesisTx   = "01000000010000000000000000000000000000000000000000000000000000000000000000ffffffff4d04ffff001d0104455468652054696d65732030332f4a616e2f32303039204368616e63656c6c6f72206f6e206272696e6b206f66207365636f6e64206261696c6f757420666f722062616e6b73ffffffff0100f2052a01000000434104678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5fac00000000"
+	testGenesisTxID = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+)
+
+// TestDecodeRawTransaction tests decoding the genesis coinbase and a P2PKH transaction
+func TestDecodeRawTransaction(t *testing.T) {
+	t.Parallel()
+
+	tx, err := DecodeRawTransaction(testGenesisTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.TxID != testGenesisTxID || tx.Version != 1 || tx.LockTime != 0 || tx.Size != len(testGenesisTx)/2 {
+		t.Fatalf("unexpected transaction: %+v", tx)
+	}
+	if len(tx.Inputs) != 1 || !tx.Inputs[0].IsCoinbase() || tx.Inputs[0].Sequence != 0xffffffff || len(tx.Inputs[0].ScriptSig) != 77 {
+		t.Fatalf("unexpected inputs: %+v", tx.Inputs)
+	}
+	if len(tx.Outputs) != 1 || tx.Outputs[0].Value != 50*SatoshisPerBSV || tx.Outputs[0].ScriptPubKey(NetworkMain).Type != scriptTypePubKey {
+		t.Fatalf("unexpected outputs: %+v", tx.Outputs)
+	}
+	if hex.EncodeToString(tx.Bytes()) != testGenesisTx {
+		t.Fatal("expected the same bytes")
+	}
+
+	// P2PKH output (to the hash160 of zeros)
+	if tx, err = DecodeRawTransaction(testRawTx); err != nil {
+		t.Fatal(err)
+	}
+	if tx.TxID != testRawTxID || rawTransactionID(testRawTx) != tx.TxID {
+		t.Fatalf("expected txid %s got: %s", testRawTxID, tx.TxID)
+	}
+	scriptPubKey := tx.Outputs[0].ScriptPubKey(NetworkMain)
+	if scriptPubKey.Type != scriptTypePubKeyHash || scriptPubKey.Address() != "1111111111111111111114oLvT2" {
+		t.Fatalf("unexpected script pubkey: %+v", scriptPubKey)
+	}
+}
+
+// TestRawTransaction_RoundTrip tests serializing and decoding larger fields (3 byte var ints)
+func TestRawTransaction_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	tx := &RawTransaction{
+		Inputs: []RawInput{
+			{PreviousTxID: testGenesisTxID, PreviousVout: 3, ScriptSig: bytes.Repeat([]byte{0x01}, 300), Sequence: 7},
+			{PreviousTxID: testRawTxID, ScriptSig: []byte{}, Sequence: 0xffffffff},
+		},
+		LockTime: 600000,
+		Outputs: []RawOutput{
+			{LockingScript: append([]byte{0xa9, 0x14}, append(make([]byte, 20), 0x87)...), Value: 1234},
+			{LockingScript: []byte{0x00, 0x6a, 0x02, 0x68, 0x69}},
+		},
+		Version: 2,
+	}
+
+	decoded, err := DecodeRawTransaction(hex.EncodeToString(tx.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded.Bytes(), tx.Bytes()) || decoded.Inputs[0].PreviousTxID != testGenesisTxID || len(decoded.Inputs[0].ScriptSig) != 300 {
+		t.Fatalf("unexpected round trip: %+v", decoded)
+	}
+
+	// Maps onto a Transaction
+	mapped := decoded.ToTransaction(NetworkTest)
+	if mapped.TxID != decoded.TxID || mapped.Version != 2 || mapped.LockTime != 600000 || mapped.ValueOut != 1234 {
+		t.Fatalf("unexpected transaction: %+v", mapped)
+	}
+	if mapped.Vin[0].TxID != testGenesisTxID || mapped.Vin[0].Vout != 3 || mapped.Vin[0].Sequence != 7 || mapped.Vin[1].N != 1 {
+		t.Fatalf("unexpected inputs: %+v", mapped.Vin)
+	}
+	if mapped.Vout[0].ScriptPubKey.Type != scriptTypeScriptHash || !strings.HasPrefix(mapped.Vout[0].ScriptPubKey.Address(), "2") {
+		t.Fatalf("expected a testnet P2SH address got: %+v", mapped.Vout[0].ScriptPubKey)
+	}
+	if data, err := mapped.Vout[1].OpReturnData(); err != nil || string(data[0]) != "hi" {
+		t.Fatalf("unexpected OP_RETURN data: %q %v", data, err)
+	}
+	if err = decoded.Verify(mapped); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestRawTransaction_Verify tests comparing the api transaction to the raw transaction
+func TestRawTransaction_Verify(t *testing.T) {
+	t.Parallel()
+
+	raw, err := DecodeRawTransaction(testGenesisTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The api shape (coinbase has no txid, no script hex)
+	tx := &Transaction{
+		TxID:    testGenesisTxID,
+		Version: 1,
+		Vin:     []TransactionInput{{Sequence: 0xffffffff}},
+		Vout:    []TransactionOutput{{ValueSatoshis: 5000000000}},
+	}
+	if err = raw.Verify(tx); err != nil {
+		t.Fatal(err)
+	}
+	tx.RawTx = testGenesisTx
+	if err = tx.VerifyRawTx(); err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []func(tx *Transaction){
+		func(tx *Transaction) { tx.TxID = testRawTxID },
+		func(tx *Transaction) { tx.LockTime = 1 },
+		func(tx *Transaction) { tx.Vin[0].TxID = testRawTxID },
+		func(tx *Transaction) { tx.Vin[0].Sequence = 1 },
+		func(tx *Transaction) { tx.Vout[0].Value = 1 },
+		func(tx *Transaction) { tx.Vout[0].ScriptPubKey.Hex = "76a9" },
+		func(tx *Transaction) { tx.Vout = nil },
+	}
+	for i, change := range tests {
+		changed := *raw.ToTransaction(NetworkMain)
+		change(&changed)
+		if err = raw.Verify(&changed); !errors.Is(err, ErrTransactionMismatch) {
+			t.Fatalf("%d: expected ErrTransactionMismatch got: %v", i, err)
+		}
+	}
+}
+
+// TestDecodeRawTransaction_Errors tests invalid raw transactions
+func TestDecodeRawTransaction_Errors(t *testing.T) {
+	t.Parallel()
+
+	var tests = []string{
+		"",
+		"zz",
+		testGenesisTx[:len(testGenesisTx)-2],
+		testGenesisTx + "00",
+		"01000000ff",
+		"0100000001" + strings.Repeat("00", 36) + "ffffffffffffffff",
+	}
+	for _, test := range tests {
+		if _, err := DecodeRawTransaction(test); !errors.Is(err, ErrInvalidTransaction) {
+			t.Fatalf("%q: expected ErrInvalidTransaction got: %v", test, err)
+		}
+	}
+}
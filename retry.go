@@ -0,0 +1,79 @@
+package bitindex
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// isIdempotentMethod returns true if the HTTP method is safe to retry
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// isRetryableStatus returns true for the known transient statuses
+func isRetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// isRetryableError returns true for transient network errors (connection resets, timeouts, etc.)
+func isRetryableError(err error) bool {
+
+	// The caller gave up, never retry
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	// Connection was reset, refused or closed early
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNABORTED) || errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	// Network timeouts
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// isTransientFailure returns true if the attempt failed with a transient error or status
+func isTransientFailure(response *requestResponse, err error) bool {
+	if err != nil {
+		return isRetryableError(err)
+	}
+	return response != nil && isRetryableStatus(response.StatusCode)
+}
+
+// shouldRetry returns true if the request is safe to retry and the failure is transient
+//
+// Only idempotent requests are retried (broadcasts are handled in SendTransaction)
+func (c *Client) shouldRetry(ctx context.Context, req *apiRequest, attempt int,
+	response *requestResponse, err error) bool {
+	return req.Idempotent && attempt < c.retryCount && ctx.Err() == nil && isTransientFailure(response, err)
+}
+
+// waitForRetry will sleep for the back-off interval or return the context error if done first
+func (c *Client) waitForRetry(ctx context.Context, attempt int) error {
+	timer := time.NewTimer(c.backOff.Next(attempt))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
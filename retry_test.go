@@ -0,0 +1,363 @@
+package bitindex
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// testRawTx is a raw transaction used for broadcast tests (txid: testRawTxID)
+const (
+	testRawTx   = "01000000010000000000000000000000000000000000000000000000000000000000000000ffffffff00ffffffff0100f2052a010000001976a914000000000000000000000000000000000000000088ac00000000"
+	testRawTxID = "a0792787fc14442386f67a9b74022c73a13494ae00b985393d733e3c686268e0"
+)
+
+// newRetryOptions returns options with a fast back-off for testing
+func newRetryOptions(retryCount int) *Options {
+	options := ClientDefaultOptions()
+	options.BackOffInitialTimeout = time.Millisecond
+	options.BackOffMaxTimeout = 2 * time.Millisecond
+	options.BackOffMaximumJitterInterval = 0
+	options.RequestRetryCount = retryCount
+	return options
+}
+
+// TestIsRetryableStatus tests the isRetryableStatus()
+func TestIsRetryableStatus(t *testing.T) {
+	t.Parallel()
+
+	for _, statusCode := range []int{429, 502, 503, 504} {
+		if !isRetryableStatus(statusCode) {
+			t.Fatalf("expected %d to be retryable", statusCode)
+		}
+	}
+	for _, statusCode := range []int{200, 400, 401, 404, 422, 500, 501} {
+		if isRetryableStatus(statusCode) {
+			t.Fatalf("expected %d to not be retryable", statusCode)
+		}
+	}
+}
+
+// TestIsIdempotentMethod tests the isIdempotentMethod()
+func TestIsIdempotentMethod(t *testing.T) {
+	t.Parallel()
+
+	if !isIdempotentMethod(http.MethodGet) || !isIdempotentMethod(http.MethodPut) {
+		t.Fatal("GET and PUT should be idempotent")
+	}
+	if isIdempotentMethod(http.MethodPost) || isIdempotentMethod(http.MethodPatch) {
+		t.Fatal("POST and PATCH should not be idempotent")
+	}
+}
+
+// timeoutError is a net.Error that timed out
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// TestIsRetryableError tests the isRetryableError()
+func TestIsRetryableError(t *testing.T) {
+	t.Parallel()
+
+	connectionReset := &url.Error{Op: "Get", URL: "https://example.com", Err: &net.OpError{
+		Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET),
+	}}
+
+	var tests = []struct {
+		err      error
+		expected bool
+	}{
+		{nil, false},
+		{connectionReset, true},
+		{&url.Error{Op: "Get", URL: "https://example.com", Err: io.EOF}, true},
+		{&url.Error{Op: "Get", URL: "https://example.com", Err: timeoutError{}}, true},
+		{&url.Error{Op: "Get", URL: "https://example.com", Err: context.Canceled}, false},
+		{context.DeadlineExceeded, false},
+		{errors.New("unsupported protocol scheme"), false},
+	}
+
+	for _, test := range tests {
+		if retryable := isRetryableError(test.err); retryable != test.expected {
+			t.Fatalf("%v: expected %t got: %t", test.err, test.expected, retryable)
+		}
+	}
+}
+
+// TestClient_Retry tests the retry policy
+func TestClient_Retry(t *testing.T) {
+	t.Parallel()
+
+	t.Run("transient status is retried", func(t *testing.T) {
+		var requests int32
+		client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&requests, 1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte(`{"addrStr":"test"}`))
+		}), newRetryOptions(2))
+
+		if _, err := client.AddressInfo("test"); err != nil {
+			t.Fatal(err)
+		}
+		if count := atomic.LoadInt32(&requests); count != 3 {
+			t.Fatalf("expected 3 requests, got: %d", count)
+		}
+	})
+
+	t.Run("gives up after the retry count", func(t *testing.T) {
+		var requests int32
+		client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			w.WriteHeader(http.StatusBadGateway)
+		}), newRetryOptions(2))
+
+		if _, err := client.AddressInfo("test"); !errors.Is(err, ErrServerError) {
+			t.Fatalf("expected ErrServerError, got: %v", err)
+		}
+		if count := atomic.LoadInt32(&requests); count != 3 {
+			t.Fatalf("expected 3 requests, got: %d", count)
+		}
+	})
+
+	t.Run("non transient status is not retried", func(t *testing.T) {
+		var requests int32
+		client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			w.WriteHeader(http.StatusInternalServerError)
+		}), newRetryOptions(2))
+
+		if _, err := client.AddressInfo("test"); !errors.Is(err, ErrServerError) {
+			t.Fatalf("expected ErrServerError, got: %v", err)
+		}
+		if count := atomic.LoadInt32(&requests); count != 1 {
+			t.Fatalf("expected 1 request, got: %d", count)
+		}
+	})
+
+	t.Run("read-only post is retried", func(t *testing.T) {
+		var requests int32
+		client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&requests, 1) == 1 {
+				w.WriteHeader(http.StatusGatewayTimeout)
+				return
+			}
+			_, _ = w.Write([]byte(`[]`))
+		}), newRetryOptions(2))
+
+		if _, err := client.GetUnspentTransactions(&GetUnspentTransactionsRequest{Address: "test"}); err != nil {
+			t.Fatal(err)
+		}
+		if count := atomic.LoadInt32(&requests); count != 2 {
+			t.Fatalf("expected 2 requests, got: %d", count)
+		}
+	})
+
+	t.Run("generic post is not retried", func(t *testing.T) {
+		var requests int32
+		client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}), newRetryOptions(2))
+
+		if _, err := client.Request("addrs/txs", http.MethodPost, []byte(`{}`)); err != nil {
+			t.Fatal(err)
+		}
+		if count := atomic.LoadInt32(&requests); count != 1 {
+			t.Fatalf("expected 1 request, got: %d", count)
+		}
+	})
+
+	t.Run("reserving an address is not retried", func(t *testing.T) {
+		var requests int32
+		client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}), newRetryOptions(2))
+
+		if _, err := client.GetXpubNextAddress("xpub", 60); !errors.Is(err, ErrServerError) {
+			t.Fatalf("expected ErrServerError, got: %v", err)
+		}
+		if count := atomic.LoadInt32(&requests); count != 1 {
+			t.Fatalf("expected 1 request, got: %d", count)
+		}
+
+		// Without a reservation it is a read
+		if _, err := client.GetXpubNextAddress("xpub", 0); !errors.Is(err, ErrServerError) {
+			t.Fatalf("expected ErrServerError, got: %v", err)
+		}
+		if count := atomic.LoadInt32(&requests); count != 4 {
+			t.Fatalf("expected 4 requests, got: %d", count)
+		}
+	})
+
+	t.Run("canceled during back-off", func(t *testing.T) {
+		options := newRetryOptions(5)
+		options.BackOffInitialTimeout = time.Second
+		options.BackOffMaxTimeout = time.Second
+
+		var requests int32
+		client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}), options)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		if _, err := client.AddressInfoWithContext(ctx, "test"); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected context.DeadlineExceeded, got: %v", err)
+		}
+		if count := atomic.LoadInt32(&requests); count != 1 {
+			t.Fatalf("expected 1 request, got: %d", count)
+		}
+	})
+}
+
+// TestClient_SendTransaction_Retry tests the broadcast retry policy
+func TestClient_SendTransaction_Retry(t *testing.T) {
+	t.Parallel()
+
+	t.Run("broadcast made it to the network", func(t *testing.T) {
+		var broadcasts int32
+		client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/v3/main/tx/send":
+				atomic.AddInt32(&broadcasts, 1)
+				w.WriteHeader(http.StatusBadGateway)
+			case "/api/v3/main/tx/" + testRawTxID:
+				_, _ = w.Write([]byte(`{"txid":"` + testRawTxID + `"}`))
+			default:
+				t.Errorf("unexpected path: %s", r.URL.Path)
+			}
+		}), newRetryOptions(2))
+
+		response, err := client.SendTransaction(testRawTx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if response.TxID != testRawTxID {
+			t.Fatalf("expected txid %s, got: %s", testRawTxID, response.TxID)
+		}
+		if count := atomic.LoadInt32(&broadcasts); count != 1 {
+			t.Fatalf("expected 1 broadcast, got: %d", count)
+		}
+	})
+
+	t.Run("broadcast is re-sent if not found", func(t *testing.T) {
+		var broadcasts int32
+		client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/v3/main/tx/send":
+				if atomic.AddInt32(&broadcasts, 1) == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				_, _ = w.Write([]byte(`{"txid":"` + testRawTxID + `"}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}), newRetryOptions(2))
+
+		response, err := client.SendTransaction(testRawTx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if response.TxID != testRawTxID {
+			t.Fatalf("expected txid %s, got: %s", testRawTxID, response.TxID)
+		}
+		if count := atomic.LoadInt32(&broadcasts); count != 2 {
+			t.Fatalf("expected 2 broadcasts, got: %d", count)
+		}
+	})
+
+	t.Run("re-send already in the mempool", func(t *testing.T) {
+		var broadcasts int32
+		client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/v3/main/tx/send":
+				if atomic.AddInt32(&broadcasts, 1) == 1 {
+					w.WriteHeader(http.StatusBadGateway)
+					return
+				}
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"success":false,"code":18,"message":"txn-already-in-mempool"}`))
+			default:
+				w.WriteHeader(http.StatusNotFound) // the indexer has not seen it yet
+			}
+		}), newRetryOptions(2))
+
+		response, err := client.SendTransaction(testRawTx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if response.TxID != testRawTxID {
+			t.Fatalf("expected txid %s, got: %s", testRawTxID, response.TxID)
+		}
+		if count := atomic.LoadInt32(&broadcasts); count != 2 {
+			t.Fatalf("expected 2 broadcasts, got: %d", count)
+		}
+	})
+
+	t.Run("failed lookup is not re-sent", func(t *testing.T) {
+		var broadcasts, lookups int32
+		client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/v3/main/tx/send":
+				atomic.AddInt32(&broadcasts, 1)
+				w.WriteHeader(http.StatusBadGateway)
+			default:
+				atomic.AddInt32(&lookups, 1)
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}), newRetryOptions(2))
+
+		if _, err := client.SendTransaction(testRawTx); !errors.Is(err, ErrServerError) {
+			t.Fatalf("expected ErrServerError, got: %v", err)
+		}
+		if count := atomic.LoadInt32(&broadcasts); count != 1 {
+			t.Fatalf("expected 1 broadcast, got: %d", count)
+		}
+		if count := atomic.LoadInt32(&lookups); count != 3 {
+			t.Fatalf("expected 3 lookups (with retries), got: %d", count)
+		}
+	})
+
+	t.Run("rejected broadcast is not re-sent", func(t *testing.T) {
+		var broadcasts int32
+		client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&broadcasts, 1)
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"success":false,"code":16,"message":"mandatory-script-verify-flag-failed"}`))
+		}), newRetryOptions(2))
+
+		if _, err := client.SendTransaction(testRawTx); !errors.Is(err, ErrBadRequest) {
+			t.Fatalf("expected ErrBadRequest, got: %v", err)
+		}
+		if count := atomic.LoadInt32(&broadcasts); count != 1 {
+			t.Fatalf("expected 1 broadcast, got: %d", count)
+		}
+	})
+}
+
+// TestRawTransactionID tests the rawTransactionID()
+func TestRawTransactionID(t *testing.T) {
+	t.Parallel()
+
+	if txID := rawTransactionID(testRawTx); txID != testRawTxID {
+		t.Fatalf("expected txid %s, got: %s", testRawTxID, txID)
+	}
+	if txID := rawTransactionID("not-hex"); len(txID) != 0 {
+		t.Fatalf("expected an empty txid, got: %s", txID)
+	}
+}
@@ -0,0 +1,15 @@
+package bitindex
+
+import (
+	"crypto/sha256"
+
+	"golang.org/x/crypto/ripemd160" //nolint:staticcheck // RIPEMD-160 is required for the P2PKH addresses
+)
+
+// hash160 returns ripemd160(sha256(data)) (the hash in a P2PKH address)
+func hash160(data []byte) []byte {
+	sum := sha256.Sum256(data)
+	hasher := ripemd160.New()
+	_, _ = hasher.Write(sum[:])
+	return hasher.Sum(nil)
+}
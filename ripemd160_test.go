@@ -0,0 +1,20 @@
+package bitindex
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// TestHash160 tests the address hash of a public key
+func TestHash160(t *testing.T) {
+	t.Parallel()
+
+	if digest := hex.EncodeToString(hash160(nil)); digest != "b472a266d0bd89c13706a4132ccfb16f7c3b9fcb" {
+		t.Fatalf("unexpected digest: %s", digest)
+	}
+
+	publicKey, _ := hex.DecodeString("0250863ad64a87ae8a2fe83c1af1a8403cb53f53e486d8511dad8a04887e5b2352")
+	if address := hash160Address(hash160(publicKey), NetworkMain, false); address != "1PMycacnJaSqwwJqjawXBErnLsZ7RkXUAs" {
+		t.Fatalf("unexpected address: %s", address)
+	}
+}
@@ -0,0 +1,325 @@
+package bitindex
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Satoshis is an exact amount in satoshis (1 BSV = 100,000,000 satoshis)
+//
+// The api returns BSV amounts as decimal numbers (IE: 0.0001), Satoshis parses them exactly
+// (no float rounding) and writes them back as BSV decimals in JSON
+type Satoshis int64
+
+// SatoshisPerBSV is the number of satoshis in one BSV
+const SatoshisPerBSV Satoshis = 100000000
+
+// bsvDecimals is the number of decimal places in a BSV amount
+const bsvDecimals = 8
+
+// ErrInvalidAmount is returned when a BSV amount can not be parsed
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// NewSatoshisFromBSV will convert a float BSV amount (rounded to the nearest satoshi)
+func NewSatoshisFromBSV(bsv float64) Satoshis {
+	return Satoshis(math.Round(bsv * float64(SatoshisPerBSV)))
+}
+
+// ParseBSV will parse a decimal BSV amount exactly (IE: "0.00012345", "-1.5" or "1e-8")
+//
+// More than 8 decimal places (fractions of a satoshi) is an error
+func ParseBSV(value string) (Satoshis, error) {
+	return parseBSV(value, false)
+}
+
+// parseBSV will parse the BSV amount (round is used for api values with float noise)
+func parseBSV(value string, round bool) (Satoshis, error) {
+	value = strings.TrimSpace(value)
+	if !isDecimal(value) {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, value)
+	}
+
+	amount, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, value)
+	}
+	amount.Mul(amount, new(big.Rat).SetInt64(int64(SatoshisPerBSV)))
+
+	// Fractions of a satoshi
+	satoshis := new(big.Int)
+	if amount.IsInt() {
+		satoshis.Set(amount.Num())
+	} else if !round {
+		return 0, fmt.Errorf("%w: %q has more than %d decimal places", ErrInvalidAmount, value, bsvDecimals)
+	} else {
+		// Round half away from zero
+		remainder := new(big.Int)
+		satoshis.QuoRem(amount.Num(), amount.Denom(), remainder)
+		if new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(amount.Denom()) >= 0 {
+			satoshis.Add(satoshis, big.NewInt(int64(amount.Sign())))
+		}
+	}
+
+	if !satoshis.IsInt64() {
+		return 0, fmt.Errorf("%w: %q is out of range", ErrInvalidAmount, value)
+	}
+	return Satoshis(satoshis.Int64()), nil
+}
+
+// isDecimal returns true for [+-]digits[.digits][e[+-]digits] (no hex, fractions or spaces)
+func isDecimal(value string) bool {
+	if len(value) > 0 && (value[0] == '-' || value[0] == '+') {
+		value = value[1:]
+	}
+	mantissa, exponent := value, ""
+	if index := strings.IndexAny(value, "eE"); index >= 0 {
+		mantissa, exponent = value[:index], value[index+1:]
+		if len(exponent) > 0 && (exponent[0] == '-' || exponent[0] == '+') {
+			exponent = exponent[1:]
+		}
+		if len(exponent) == 0 || !isDigits(exponent) {
+			return false
+		}
+	}
+	whole, fraction := mantissa, ""
+	if index := strings.IndexByte(mantissa, '.'); index >= 0 {
+		whole, fraction = mantissa[:index], mantissa[index+1:]
+	}
+	return len(whole)+len(fraction) > 0 && isDigits(whole) && isDigits(fraction)
+}
+
+// isDigits returns true if the value only contains 0-9
+func isDigits(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if value[i] < '0' || value[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// BSV returns the amount in BSV (float, use String() for an exact value)
+func (s Satoshis) BSV() float64 {
+	return float64(s) / float64(SatoshisPerBSV)
+}
+
+// Int64 returns the amount in satoshis
+func (s Satoshis) Int64() int64 {
+	return int64(s)
+}
+
+// Add returns the sum of the amounts
+func (s Satoshis) Add(amount Satoshis) Satoshis {
+	return s + amount
+}
+
+// Sub returns the difference of the amounts
+func (s Satoshis) Sub(amount Satoshis) Satoshis {
+	return s - amount
+}
+
+// Mul returns the amount multiplied by n
+func (s Satoshis) Mul(n int64) Satoshis {
+	return s * Satoshis(n)
+}
+
+// String returns the exact BSV decimal (IE: 0.00012345 or 1.5)
+func (s Satoshis) String() string {
+	value := uint64(s)
+	sign := ""
+	if s < 0 {
+		sign = "-"
+		value = uint64(-s)
+	}
+
+	whole := value / uint64(SatoshisPerBSV)
+	fraction := strings.TrimRight(fmt.Sprintf("%08d", value%uint64(SatoshisPerBSV)), "0")
+	if len(fraction) == 0 {
+		return sign + strconv.FormatUint(whole, 10)
+	}
+	return sign + strconv.FormatUint(whole, 10) + "." + fraction
+}
+
+// MarshalJSON writes the amount as a BSV decimal number
+func (s Satoshis) MarshalJSON() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalJSON reads a BSV decimal number or string (null is zero)
+func (s *Satoshis) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*s = 0
+		return nil
+	}
+
+	value := string(data)
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		if value = value[1 : len(value)-1]; len(value) == 0 {
+			*s = 0
+			return nil
+		}
+	}
+
+	// Api values can have float noise (IE: 0.30000000000000004)
+	amount, err := parseBSV(value, true)
+	if err != nil {
+		return err
+	}
+	*s = amount
+	return nil
+}
+
+// fillAmount sets the BSV or satoshi amount from the other (if only one was returned)
+func fillAmount(amount, satoshis *Satoshis) {
+	if *amount == 0 && *satoshis != 0 {
+		*amount = *satoshis
+	} else if *satoshis == 0 && *amount != 0 {
+		*satoshis = *amount
+	}
+}
+
+// The satoshi fields (IE: balanceSat, valueSat) are integers on the wire, not BSV decimals, so the
+// response structs read and write them through int64 fields that shadow the Satoshis fields
+// (the shallower field wins in encoding/json)
+
+// addressInfoSatoshis are the integer satoshi fields of the AddressInfo
+type addressInfoSatoshis struct {
+	BalanceSatoshis            int64 `json:"balanceSat"`
+	TotalReceivedSatoshis      int64 `json:"totalReceivedSat"`
+	TotalSentSatoshis          int64 `json:"totalSentSat"`
+	UnconfirmedBalanceSatoshis int64 `json:"unconfirmedBalanceSat"`
+}
+
+// UnmarshalJSON fills the BSV amounts from the satoshi amounts (and the other way around)
+func (a *AddressInfo) UnmarshalJSON(data []byte) error {
+	type addressInfo AddressInfo
+	wire := struct {
+		*addressInfo
+		BalanceSatoshis            int64 `json:"balanceSat"`
+		TotalReceivedSatoshis      int64 `json:"totalReceivedSat"`
+		TotalSentSatoshis          int64 `json:"totalSentSat"`
+		UnconfirmedBalanceSatoshis int64 `json:"unconfirmedBalanceSat"`
+	}{addressInfo: (*addressInfo)(a)}
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	a.BalanceSatoshis = Satoshis(wire.BalanceSatoshis)
+	a.TotalReceivedSatoshis = Satoshis(wire.TotalReceivedSatoshis)
+	a.TotalSentSatoshis = Satoshis(wire.TotalSentSatoshis)
+	a.UnconfirmedBalanceSatoshis = Satoshis(wire.UnconfirmedBalanceSatoshis)
+
+	fillAmount(&a.Balance, &a.BalanceSatoshis)
+	fillAmount(&a.TotalReceived, &a.TotalReceivedSatoshis)
+	fillAmount(&a.TotalSent, &a.TotalSentSatoshis)
+	fillAmount(&a.UnconfirmedBalance, &a.UnconfirmedBalanceSatoshis)
+	return nil
+}
+
+// MarshalJSON writes the satoshi amounts as integers (the BSV amounts as decimals)
+func (a AddressInfo) MarshalJSON() ([]byte, error) {
+	type addressInfo AddressInfo
+	return json.Marshal(struct {
+		addressInfo
+		BalanceSatoshis            int64 `json:"balanceSat"`
+		TotalReceivedSatoshis      int64 `json:"totalReceivedSat"`
+		TotalSentSatoshis          int64 `json:"totalSentSat"`
+		UnconfirmedBalanceSatoshis int64 `json:"unconfirmedBalanceSat"`
+	}{
+		addressInfo(a), a.BalanceSatoshis.Int64(), a.TotalReceivedSatoshis.Int64(),
+		a.TotalSentSatoshis.Int64(), a.UnconfirmedBalanceSatoshis.Int64(),
+	})
+}
+
+// unspentTransactionSatoshis are the integer satoshi fields of the UnspentTransaction
+type unspentTransactionSatoshis struct {
+	Satoshis int64 `json:"satoshis"`
+	Value    int64 `json:"value"`
+}
+
+// UnmarshalJSON fills the BSV amount from the satoshi amount (and the other way around)
+func (u *UnspentTransaction) UnmarshalJSON(data []byte) error {
+	type unspentTransaction UnspentTransaction
+	wire := struct {
+		*unspentTransaction
+		Satoshis int64 `json:"satoshis"`
+		Value    int64 `json:"value"`
+	}{unspentTransaction: (*unspentTransaction)(u)}
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	u.Satoshis, u.Value = Satoshis(wire.Satoshis), Satoshis(wire.Value)
+
+	if u.Satoshis == 0 {
+		u.Satoshis = u.Value
+	}
+	fillAmount(&u.Amount, &u.Satoshis)
+	if u.Value == 0 {
+		u.Value = u.Satoshis
+	}
+	return nil
+}
+
+// MarshalJSON writes the satoshi amounts as integers (the BSV amount as a decimal)
+func (u UnspentTransaction) MarshalJSON() ([]byte, error) {
+	type unspentTransaction UnspentTransaction
+	return json.Marshal(struct {
+		unspentTransaction
+		Satoshis int64 `json:"satoshis"`
+		Value    int64 `json:"value"`
+	}{unspentTransaction(u), u.Satoshis.Int64(), u.Value.Int64()})
+}
+
+// UnmarshalJSON fills the BSV amount from the satoshi amount (and the other way around)
+func (v *TransactionInput) UnmarshalJSON(data []byte) error {
+	type vin TransactionInput
+	wire := struct {
+		*vin
+		ValueSatoshis int64 `json:"valueSat"`
+	}{vin: (*vin)(v)}
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	v.ValueSatoshis = Satoshis(wire.ValueSatoshis)
+	fillAmount(&v.Value, &v.ValueSatoshis)
+	return nil
+}
+
+// MarshalJSON writes the satoshi amount as an integer (the BSV amount as a decimal)
+func (v TransactionInput) MarshalJSON() ([]byte, error) {
+	type vin TransactionInput
+	return json.Marshal(struct {
+		vin
+		ValueSatoshis int64 `json:"valueSat"`
+	}{vin(v), v.ValueSatoshis.Int64()})
+}
+
+// UnmarshalJSON fills the BSV amount from the satoshi amount (and the other way around)
+func (v *TransactionOutput) UnmarshalJSON(data []byte) error {
+	type vout TransactionOutput
+	wire := struct {
+		*vout
+		ValueSatoshis int64 `json:"valueSat"`
+	}{vout: (*vout)(v)}
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	v.ValueSatoshis = Satoshis(wire.ValueSatoshis)
+	fillAmount(&v.Value, &v.ValueSatoshis)
+	return nil
+}
+
+// MarshalJSON writes the satoshi amount as an integer (the BSV amount as a decimal)
+func (v TransactionOutput) MarshalJSON() ([]byte, error) {
+	type vout TransactionOutput
+	return json.Marshal(struct {
+		vout
+		ValueSatoshis int64 `json:"valueSat"`
+	}{vout(v), v.ValueSatoshis.Int64()})
+}
@@ -0,0 +1,180 @@
+package bitindex
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestParseBSV tests the ParseBSV()
+func TestParseBSV(t *testing.T) {
+	t.Parallel()
+
+	var tests = []struct {
+		input         string
+		expected      Satoshis
+		expectedError bool
+	}{
+		{"0", 0, false},
+		{"1", 100000000, false},
+		{"1.5", 150000000, false},
+		{"0.00000001", 1, false},
+		{"-0.00012345", -12345, false},
+		{"+21000000", 2100000000000000, false},
+		{".5", 50000000, false},
+		{"1e-8", 1, false},
+		{"1.2345E2", 12345000000, false},
+		{" 0.1 ", 10000000, false},
+		{"0.000000001", 0, true},
+		{"0.30000000000000004", 0, true},
+		{"", 0, true},
+		{".", 0, true},
+		{"1/3", 0, true},
+		{"0x10", 0, true},
+		{"1e", 0, true},
+		{"abc", 0, true},
+		{"100000000000", 0, true},
+	}
+
+	for _, test := range tests {
+		output, err := ParseBSV(test.input)
+		if test.expectedError {
+			if !errors.Is(err, ErrInvalidAmount) {
+				t.Fatalf("%q: expected ErrInvalidAmount got: %v", test.input, err)
+			}
+			continue
+		} else if err != nil {
+			t.Fatalf("%q: unexpected error: %v", test.input, err)
+		} else if output != test.expected {
+			t.Fatalf("%q: expected %d got: %d", test.input, test.expected, output)
+		}
+	}
+}
+
+// TestSatoshis_String tests the String() and conversions
+func TestSatoshis_String(t *testing.T) {
+	t.Parallel()
+
+	var tests = []struct {
+		input    Satoshis
+		expected string
+	}{
+		{0, "0"},
+		{1, "0.00000001"},
+		{12345, "0.00012345"},
+		{150000000, "1.5"},
+		{-100000001, "-1.00000001"},
+		{2100000000000000, "21000000"},
+	}
+
+	for _, test := range tests {
+		if output := test.input.String(); output != test.expected {
+			t.Fatalf("%d: expected %s got: %s", test.input, test.expected, output)
+		}
+		if parsed, err := ParseBSV(test.expected); err != nil || parsed != test.input {
+			t.Fatalf("%s: expected to round trip to %d got: %d %v", test.expected, test.input, parsed, err)
+		}
+	}
+
+	amount := NewSatoshisFromBSV(0.1).Add(NewSatoshisFromBSV(0.2))
+	if amount != 30000000 || amount.String() != "0.3" || amount.BSV() != 0.3 || amount.Int64() != 30000000 {
+		t.Fatalf("expected exactly 0.3 got: %s", amount)
+	}
+	if amount.Sub(1).Mul(2) != 59999998 {
+		t.Fatalf("unexpected arithmetic result: %d", amount.Sub(1).Mul(2))
+	}
+}
+
+// TestSatoshis_JSON tests the JSON (un)marshalling
+func TestSatoshis_JSON(t *testing.T) {
+	t.Parallel()
+
+	var values struct {
+		Empty  Satoshis `json:"empty"`
+		Noise  Satoshis `json:"noise"`
+		Null   Satoshis `json:"null"`
+		Number Satoshis `json:"number"`
+		String Satoshis `json:"string"`
+	}
+	err := json.Unmarshal([]byte(`{"empty":"","noise":0.30000000000000004,"null":null,"number":0.00012345,"string":"1.5"}`), &values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values.Empty != 0 || values.Noise != 30000000 || values.Null != 0 || values.Number != 12345 || values.String != 150000000 {
+		t.Fatalf("unexpected values: %+v", values)
+	}
+
+	var data []byte
+	if data, err = json.Marshal(values); err != nil {
+		t.Fatal(err)
+	} else if string(data) != `{"empty":0,"noise":0.3,"null":0,"number":0.00012345,"string":1.5}` {
+		t.Fatalf("unexpected JSON: %s", data)
+	}
+
+	if err = json.Unmarshal([]byte(`{"number":true}`), &values); err == nil {
+		t.Fatal("expected an error for a bool")
+	}
+}
+
+// TestSatoshis_ResponseFields tests the BSV & satoshi fallbacks in the response structs
+func TestSatoshis_ResponseFields(t *testing.T) {
+	t.Parallel()
+
+	// Satoshis only
+	var info AddressInfo
+	if err := json.Unmarshal([]byte(`{"addrStr":"1abc","balanceSat":12345,"totalReceivedSat":20000}`), &info); err != nil {
+		t.Fatal(err)
+	} else if info.Balance != 12345 || info.TotalReceived != 20000 || info.Address != "1abc" {
+		t.Fatalf("expected the BSV amounts from the satoshis got: %+v", info)
+	}
+
+	// BSV only
+	if err := json.Unmarshal([]byte(`{"balance":0.00012345,"totalSent":0.1}`), &info); err != nil {
+		t.Fatal(err)
+	} else if info.BalanceSatoshis != 12345 || info.TotalSentSatoshis != 10000000 {
+		t.Fatalf("expected the satoshis from the BSV amounts got: %+v", info)
+	}
+
+	var utxo UnspentTransaction
+	if err := json.Unmarshal([]byte(`{"txid":"abc","value":5000}`), &utxo); err != nil {
+		t.Fatal(err)
+	} else if utxo.Amount != 5000 || utxo.Satoshis != 5000 {
+		t.Fatalf("expected the amount from the value got: %+v", utxo)
+	}
+
+	var tx Transaction
+	err := json.Unmarshal([]byte(`{"fees":0.00000226,"valueIn":0.3,"valueOut":0.29999774,
+		"vin":[{"valueSat":30000000}],"vout":[{"value":0.29999774}]}`), &tx)
+	if err != nil {
+		t.Fatal(err)
+	} else if tx.ValueIn.Sub(tx.ValueOut) != tx.Fees || tx.Fees != 226 {
+		t.Fatalf("expected exact fees got: %s", tx.Fees)
+	} else if tx.Vin[0].Value != 30000000 || tx.Vout[0].ValueSatoshis != 29999774 {
+		t.Fatalf("expected the vin/vout amounts to be filled got: %+v %+v", tx.Vin[0], tx.Vout[0])
+	}
+
+	// The satoshi fields are written as integers (and read back the same)
+	data, err := json.Marshal(&tx.Vout[0])
+	if err != nil {
+		t.Fatal(err)
+	} else if !strings.Contains(string(data), `"value":0.29999774`) || !strings.Contains(string(data), `"valueSat":29999774`) {
+		t.Fatalf("unexpected JSON: %s", data)
+	}
+	if data, err = json.Marshal(info); err != nil {
+		t.Fatal(err)
+	} else if !strings.Contains(string(data), `"balanceSat":12345`) || !strings.Contains(string(data), `"balance":0.00012345`) {
+		t.Fatalf("unexpected JSON: %s", data)
+	}
+	var decoded AddressInfo
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	} else if decoded.BalanceSatoshis != info.BalanceSatoshis || decoded.TotalSent != info.TotalSent {
+		t.Fatalf("expected the same amounts got: %+v", decoded)
+	}
+	if data, err = json.Marshal(UnspentTransaction{Amount: 5000, Satoshis: 5000, Value: 5000}); err != nil {
+		t.Fatal(err)
+	} else if !strings.Contains(string(data), `"satoshis":5000`) || !strings.Contains(string(data), `"value":5000}`) || !strings.Contains(string(data), `"amount":0.00005`) {
+		t.Fatalf("unexpected JSON: %s", data)
+	}
+}
@@ -0,0 +1,94 @@
+package bitindex
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Script opcodes used for the data (OP_RETURN) outputs
+const (
+	opFalse     byte = 0x00
+	opPushData1 byte = 0x4c
+	opPushData2 byte = 0x4d
+	opPushData4 byte = 0x4e
+	opReturn    byte = 0x6a
+)
+
+// scriptTypeNullData is the script type for data (OP_RETURN) outputs
+const scriptTypeNullData = "nulldata"
+
+// ErrInvalidScript is returned when a script can not be parsed
+var ErrInvalidScript = errors.New("invalid script")
+
+// Address returns the first address (empty for data and non-standard outputs)
+func (s *ScriptPubKey) Address() string {
+	if len(s.Addresses) > 0 {
+		return s.Addresses[0]
+	}
+	return ""
+}
+
+// IsOpReturn returns true for a data output (OP_RETURN or OP_FALSE OP_RETURN)
+func (s *ScriptPubKey) IsOpReturn() bool {
+	if s.Type == scriptTypeNullData {
+		return true
+	}
+	script := strings.ToLower(s.Hex)
+	return strings.HasPrefix(script, "6a") || strings.HasPrefix(script, "006a")
+}
+
+// OpReturnData returns the data pushed after the OP_RETURN (IE: protocol prefix, data, etc.)
+func (s *ScriptPubKey) OpReturnData() ([][]byte, error) {
+	if !s.IsOpReturn() {
+		return nil, fmt.Errorf("%w: not an OP_RETURN output", ErrInvalidScript)
+	}
+
+	script, err := hex.DecodeString(s.Hex)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidScript, err.Error())
+	}
+
+	// Skip the OP_FALSE OP_RETURN (or OP_RETURN)
+	if len(script) > 0 && script[0] == opFalse {
+		script = script[1:]
+	}
+	if len(script) == 0 || script[0] != opReturn {
+		return nil, fmt.Errorf("%w: missing OP_RETURN", ErrInvalidScript)
+	}
+	return parsePushes(script[1:])
+}
+
+// parsePushes returns the data of the push operations (any other opcode is an error)
+func parsePushes(script []byte) (pushes [][]byte, err error) {
+	for len(script) > 0 {
+		opcode := script[0]
+		script = script[1:]
+
+		// Size of the push
+		var size int
+		switch {
+		case opcode == opFalse:
+			size = 0
+		case opcode < opPushData1:
+			size = int(opcode)
+		case opcode == opPushData1 && len(script) >= 1:
+			size, script = int(script[0]), script[1:]
+		case opcode == opPushData2 && len(script) >= 2:
+			size, script = int(binary.LittleEndian.Uint16(script)), script[2:]
+		case opcode == opPushData4 && len(script) >= 4:
+			size, script = int(binary.LittleEndian.Uint32(script)), script[4:]
+		default:
+			return nil, fmt.Errorf("%w: unexpected opcode 0x%02x", ErrInvalidScript, opcode)
+		}
+
+		if size < 0 || size > len(script) {
+			return nil, fmt.Errorf("%w: push of %d bytes exceeds the script", ErrInvalidScript, size)
+		}
+		pushes = append(pushes, script[:size])
+		script = script[size:]
+	}
+	return
+}
@@ -0,0 +1,56 @@
+package bitindex
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestScriptPubKey_OpReturnData tests the IsOpReturn() and OpReturnData()
+func TestScriptPubKey_OpReturnData(t *testing.T) {
+	t.Parallel()
+
+	var tests = []struct {
+		script        ScriptPubKey
+		expected      []string
+		expectedError bool
+	}{
+		{ScriptPubKey{Hex: "006a0568656c6c6f05776f726c64"}, []string{"hello", "world"}, false},
+		{ScriptPubKey{Hex: "6a0568656c6c6f"}, []string{"hello"}, false},
+		{ScriptPubKey{Hex: "6a4c0568656c6c6f00"}, []string{"hello", ""}, false},
+		{ScriptPubKey{Hex: "6a4d050068656c6c6f"}, []string{"hello"}, false},
+		{ScriptPubKey{Hex: "6a4e0500000068656c6c6f"}, []string{"hello"}, false},
+		{ScriptPubKey{Hex: "6a", Type: scriptTypeNullData}, nil, false},
+		{ScriptPubKey{Hex: "6a0568656c6c"}, nil, true},
+		{ScriptPubKey{Hex: "6a4d05"}, nil, true},
+		{ScriptPubKey{Hex: "6a76"}, nil, true},
+		{ScriptPubKey{Hex: "6azz"}, nil, true},
+		{ScriptPubKey{Type: scriptTypeNullData}, nil, true},
+		{ScriptPubKey{Hex: "76a914000000000000000000000000000000000000000088ac", Type: "pubkeyhash"}, nil, true},
+	}
+
+	for _, test := range tests {
+		data, err := test.script.OpReturnData()
+		if test.expectedError {
+			if !errors.Is(err, ErrInvalidScript) {
+				t.Fatalf("%s: expected ErrInvalidScript got: %v", test.script.Hex, err)
+			}
+			continue
+		} else if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.script.Hex, err)
+		}
+
+		if len(data) != len(test.expected) {
+			t.Fatalf("%s: expected %d pushes got: %d", test.script.Hex, len(test.expected), len(data))
+		}
+		for i := range data {
+			if string(data[i]) != test.expected[i] {
+				t.Fatalf("%s: expected %q got: %q", test.script.Hex, test.expected[i], data[i])
+			}
+		}
+	}
+
+	p2pkh := ScriptPubKey{Addresses: []string{"1abc"}, Hex: "76a914000000000000000000000000000000000000000088ac"}
+	if p2pkh.IsOpReturn() || p2pkh.Address() != "1abc" {
+		t.Fatal("expected a p2pkh output with an address")
+	}
+}
@@ -1,33 +1,50 @@
 package bitindex
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 )
 
 // GetTransaction this endpoint retrieves the transaction info.
 //
 // For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Transactions
 func (c *Client) GetTransaction(txID string) (transaction *Transaction, err error) {
+	return c.GetTransactionWithContext(context.Background(), txID)
+}
+
+// GetTransactionWithContext is the same as GetTransaction() but will be canceled when the given context is done
+//
+// For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Transactions
+func (c *Client) GetTransactionWithContext(ctx context.Context, txID string) (transaction *Transaction, err error) {
 
 	// Create the request
-	var resp string
+	var resp *requestResponse
 	// /api/v3/network/tx/txid
-	resp, err = c.Request("tx/"+txID, http.MethodGet, nil)
+	resp, err = c.request(ctx, &apiRequest{
+		Endpoint:   "tx/" + txID,
+		Idempotent: true,
+		Method:     http.MethodGet,
+		Name:       "GetTransaction",
+	})
 	if err != nil {
 		return
 	}
 
-	// Process the response
-	transaction = new(Transaction)
-	if err = json.Unmarshal([]byte(resp), transaction); err != nil {
+	// Error from request?
+	if resp.StatusCode != http.StatusOK {
+		err = newAPIError(resp)
 		return
 	}
 
-	// Error from request?
-	if c.LastRequest.StatusCode != http.StatusOK {
-		err = fmt.Errorf("error: %s", transaction.ErrorMessage)
+	// Process the response
+	transaction = new(Transaction)
+	if err = json.Unmarshal(resp.Body, transaction); err != nil {
 		return
 	}
 	return
@@ -37,24 +54,36 @@ func (c *Client) GetTransaction(txID string) (transaction *Transaction, err erro
 //
 // For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Transactions
 func (c *Client) GetTransactionRaw(txID string) (rawTx *TransactionRaw, err error) {
+	return c.GetTransactionRawWithContext(context.Background(), txID)
+}
+
+// GetTransactionRawWithContext is the same as GetTransactionRaw() but will be canceled when the given context is done
+//
+// For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Transactions
+func (c *Client) GetTransactionRawWithContext(ctx context.Context, txID string) (rawTx *TransactionRaw, err error) {
 
 	// Create the request
-	var resp string
+	var resp *requestResponse
 	// /api/v3/network/rawtx/txid
-	resp, err = c.Request("rawtx/"+txID, http.MethodGet, nil)
+	resp, err = c.request(ctx, &apiRequest{
+		Endpoint:   "rawtx/" + txID,
+		Idempotent: true,
+		Method:     http.MethodGet,
+		Name:       "GetTransactionRaw",
+	})
 	if err != nil {
 		return
 	}
 
-	// Process the response
-	rawTx = new(TransactionRaw)
-	if err = json.Unmarshal([]byte(resp), rawTx); err != nil {
+	// Error from request?
+	if resp.StatusCode != http.StatusOK {
+		err = newAPIError(resp)
 		return
 	}
 
-	// Error from request?
-	if c.LastRequest.StatusCode != http.StatusOK {
-		err = fmt.Errorf("error: %s", rawTx.ErrorMessage)
+	// Process the response
+	rawTx = new(TransactionRaw)
+	if err = json.Unmarshal(resp.Body, rawTx); err != nil {
 		return
 	}
 	return
@@ -64,25 +93,117 @@ func (c *Client) GetTransactionRaw(txID string) (rawTx *TransactionRaw, err erro
 //
 // For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Transactions
 func (c *Client) SendTransaction(rawTx string) (response *SendTransactionResponse, err error) {
+	return c.SendTransactionWithContext(context.Background(), rawTx)
+}
+
+// SendTransactionWithContext is the same as SendTransaction() but will be canceled when the given context is done
+//
+// A broadcast is never retried blindly: after a transient failure the transaction is looked up
+// by txid (GetTransaction) and only re-sent if the lookup says it is not found. If the lookup
+// fails for any other reason the broadcast error is returned (it may have made it). A re-send
+// rejected as already known (IE: txn-already-in-mempool) is a success
+//
+// For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Transactions
+func (c *Client) SendTransactionWithContext(ctx context.Context, rawTx string) (response *SendTransactionResponse, err error) {
+
+	// The txid is used to check if a failed broadcast made it to the network
+	txID := rawTransactionID(rawTx)
 
 	// Create the request
-	var resp string
-	// /api/v3/network/tx/send
-	resp, err = c.Request("tx/send", http.MethodPost, []byte(fmt.Sprintf(`{"rawtx":"%s"}`, rawTx)))
+	var resp *requestResponse
+	for attempt := 0; ; attempt++ {
+
+		// /api/v3/network/tx/send
+		resp, err = c.request(ctx, &apiRequest{
+			Endpoint:   "tx/send",
+			Idempotent: false,
+			Method:     http.MethodPost,
+			Name:       "SendTransaction",
+			Payload:    []byte(fmt.Sprintf(`{"rawtx":"%s"}`, rawTx)),
+		})
+
+		// A re-send of a broadcast that made it after all
+		if attempt > 0 && err == nil && isAlreadyKnown(resp) {
+			response = &SendTransactionResponse{TxID: txID}
+			return
+		}
+
+		// Done, or not safe to re-send
+		if len(txID) == 0 || attempt >= c.retryCount || ctx.Err() != nil || !isTransientFailure(resp, err) {
+			break
+		}
+
+		// Wait before checking the network
+		if waitErr := c.waitForRetry(ctx, attempt); waitErr != nil {
+			err = waitErr
+			return
+		}
+
+		// Did the broadcast make it anyway? (only re-send if the network has not seen it)
+		tx, txErr := c.GetTransactionWithContext(ctx, txID)
+		if txErr == nil && tx.TxID == txID {
+			response = &SendTransactionResponse{TxID: txID}
+			return
+		} else if txErr != nil && !errors.Is(txErr, ErrNotFound) {
+			break
+		}
+	}
 	if err != nil {
 		return
 	}
 
-	// Process the response
-	response = new(SendTransactionResponse)
-	if err = json.Unmarshal([]byte(resp), response); err != nil {
+	// Error from request?
+	if resp.StatusCode != http.StatusOK {
+		err = newAPIError(resp)
 		return
 	}
 
-	// Error from request?
-	if c.LastRequest.StatusCode != http.StatusOK {
-		err = fmt.Errorf("error: %s", response.ErrorMessage)
+	// Process the response
+	response = new(SendTransactionResponse)
+	if err = json.Unmarshal(resp.Body, response); err != nil {
 		return
 	}
 	return
 }
+
+// alreadyKnownMessages are the broadcast rejections for a transaction the network already has
+var alreadyKnownMessages = []string{"already-in-mempool", "already in mempool", "already in the mempool", "already-known", "already known", "already in block chain"}
+
+// isAlreadyKnown returns true if the broadcast was rejected because the network already has the transaction
+func isAlreadyKnown(response *requestResponse) bool {
+	if response == nil || response.StatusCode == http.StatusOK {
+		return false
+	}
+	message := strings.ToLower(string(response.Body))
+	for _, known := range alreadyKnownMessages {
+		if strings.Contains(message, known) {
+			return true
+		}
+	}
+	return false
+}
+
+// rawTransactionID returns the txid of the raw transaction hex (empty if the hex is invalid)
+func rawTransactionID(rawTx string) string {
+	rawBytes, err := hex.DecodeString(rawTx)
+	if err != nil || len(rawBytes) == 0 {
+		return ""
+	}
+	return hex.EncodeToString(reverseBytes(doubleSha256(rawBytes)))
+}
+
+// doubleSha256 returns sha256(sha256(data))
+func doubleSha256(data []byte) []byte {
+	first := sha256.Sum256(data)
+	second := sha256.Sum256(first[:])
+	return second[:]
+}
+
+// reverseBytes returns a reversed copy of the bytes (txids & block hashes are displayed reversed)
+func reverseBytes(data []byte) []byte {
+	reversed := make([]byte, len(data))
+	for i := range data {
+		reversed[len(data)-1-i] = data[i]
+	}
+	return reversed
+}
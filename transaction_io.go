@@ -0,0 +1,112 @@
+package bitindex
+
+// coinbaseTxID is the previous txid of a coinbase input
+const coinbaseTxID = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// FromAddress returns the address of the output being spent (either address field)
+func (i *TransactionInput) FromAddress() string {
+	if len(i.Address) > 0 {
+		return i.Address
+	}
+	return i.AddressAddr
+}
+
+// IsCoinbase returns true if the input is a coinbase (no previous output)
+func (i *TransactionInput) IsCoinbase() bool {
+	return len(i.TxID) == 0 || i.TxID == coinbaseTxID
+}
+
+// Address returns the first address of the output (empty for data and non-standard outputs)
+func (o *TransactionOutput) Address() string {
+	return o.ScriptPubKey.Address()
+}
+
+// Addresses returns the addresses of the output
+func (o *TransactionOutput) Addresses() []string {
+	return o.ScriptPubKey.Addresses
+}
+
+// IsSpent returns true if the output has been spent
+func (o *TransactionOutput) IsSpent() bool {
+	return len(o.SpentTxID) > 0
+}
+
+// IsOpReturn returns true for a data output (OP_RETURN or OP_FALSE OP_RETURN)
+func (o *TransactionOutput) IsOpReturn() bool {
+	return o.ScriptPubKey.IsOpReturn()
+}
+
+// OpReturnData returns the data pushed after the OP_RETURN
+func (o *TransactionOutput) OpReturnData() ([][]byte, error) {
+	return o.ScriptPubKey.OpReturnData()
+}
+
+// PaysTo returns true if the output pays the address
+func (o *TransactionOutput) PaysTo(address string) bool {
+	for _, outputAddress := range o.ScriptPubKey.Addresses {
+		if outputAddress == address {
+			return true
+		}
+	}
+	return false
+}
+
+// Addresses returns the unique input and output addresses (in order of appearance)
+func (t *Transaction) Addresses() (addresses []string) {
+	seen := make(map[string]bool)
+	add := func(address string) {
+		if len(address) > 0 && !seen[address] {
+			seen[address] = true
+			addresses = append(addresses, address)
+		}
+	}
+	for i := range t.Vin {
+		add(t.Vin[i].FromAddress())
+	}
+	for i := range t.Vout {
+		for _, address := range t.Vout[i].ScriptPubKey.Addresses {
+			add(address)
+		}
+	}
+	return
+}
+
+// OutputsTo returns the outputs that pay the address
+func (t *Transaction) OutputsTo(address string) (outputs []TransactionOutput) {
+	for i := range t.Vout {
+		if t.Vout[i].PaysTo(address) {
+			outputs = append(outputs, t.Vout[i])
+		}
+	}
+	return
+}
+
+// AmountTo returns the total paid to the address
+func (t *Transaction) AmountTo(address string) (amount Satoshis) {
+	for i := range t.Vout {
+		if t.Vout[i].PaysTo(address) {
+			amount += t.Vout[i].Value
+		}
+	}
+	return
+}
+
+// AmountFrom returns the total spent from the address
+func (t *Transaction) AmountFrom(address string) (amount Satoshis) {
+	for i := range t.Vin {
+		if t.Vin[i].FromAddress() == address {
+			amount += t.Vin[i].Value
+		}
+	}
+	return
+}
+
+// OpReturnOutputs returns the data (OP_RETURN) outputs
+func (t *Transaction) OpReturnOutputs() (outputs []TransactionOutput) {
+	for i := range t.Vout {
+		if t.Vout[i].IsOpReturn() {
+			outputs = append(outputs, t.Vout[i])
+		}
+	}
+	return
+}
@@ -0,0 +1,70 @@
+package bitindex
+
+import "testing"
+
+// testTransaction returns a transaction spending from 1from to 1to (with change and a data output)
+func testTransaction() *Transaction {
+	return &Transaction{
+		TxID: "abc",
+		Vin: []TransactionInput{
+			{AddressAddr: "1from", TxID: "prev", Value: 10000, Vout: 1},
+			{Address: "1from", TxID: "prev", Value: 5000, Vout: 2},
+		},
+		Vout: []TransactionOutput{
+			{N: 0, ScriptPubKey: ScriptPubKey{Addresses: []string{"1to"}}, SpentTxID: "next", Value: 12000},
+			{N: 1, ScriptPubKey: ScriptPubKey{Addresses: []string{"1from"}}, Value: 2500},
+			{N: 2, ScriptPubKey: ScriptPubKey{Hex: "006a0568656c6c6f", Type: scriptTypeNullData}},
+		},
+	}
+}
+
+// TestTransactionInput tests the input helpers
+func TestTransactionInput(t *testing.T) {
+	t.Parallel()
+
+	tx := testTransaction()
+	if tx.Vin[0].FromAddress() != "1from" || tx.Vin[1].FromAddress() != "1from" {
+		t.Fatal("expected the address from either field")
+	}
+	if tx.Vin[0].IsCoinbase() || !(&TransactionInput{TxID: coinbaseTxID}).IsCoinbase() {
+		t.Fatal("unexpected coinbase result")
+	}
+}
+
+// TestTransactionOutput tests the output helpers
+func TestTransactionOutput(t *testing.T) {
+	t.Parallel()
+
+	tx := testTransaction()
+	if !tx.Vout[0].IsSpent() || tx.Vout[1].IsSpent() {
+		t.Fatal("unexpected spent result")
+	}
+	if tx.Vout[0].Address() != "1to" || len(tx.Vout[0].Addresses()) != 1 || tx.Vout[2].Address() != "" {
+		t.Fatal("unexpected address result")
+	}
+	if tx.Vout[0].IsOpReturn() || !tx.Vout[2].IsOpReturn() {
+		t.Fatal("unexpected OP_RETURN result")
+	}
+	if data, err := tx.Vout[2].OpReturnData(); err != nil || len(data) != 1 || string(data[0]) != "hello" {
+		t.Fatalf("unexpected OP_RETURN data: %q %v", data, err)
+	}
+}
+
+// TestTransaction_Amounts tests the transaction helpers
+func TestTransaction_Amounts(t *testing.T) {
+	t.Parallel()
+
+	tx := testTransaction()
+	if addresses := tx.Addresses(); len(addresses) != 2 || addresses[0] != "1from" || addresses[1] != "1to" {
+		t.Fatalf("unexpected addresses: %v", addresses)
+	}
+	if tx.AmountFrom("1from") != 15000 || tx.AmountTo("1from") != 2500 || tx.AmountTo("1to") != 12000 {
+		t.Fatal("unexpected amounts")
+	}
+	if outputs := tx.OutputsTo("1to"); len(outputs) != 1 || outputs[0].N != 0 {
+		t.Fatalf("unexpected outputs: %+v", outputs)
+	}
+	if outputs := tx.OpReturnOutputs(); len(outputs) != 1 || outputs[0].N != 2 {
+		t.Fatalf("unexpected OP_RETURN outputs: %+v", outputs)
+	}
+}
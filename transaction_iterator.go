@@ -0,0 +1,138 @@
+package bitindex
+
+import (
+	"context"
+	"strconv"
+)
+
+// MaxTransactionsPageSize is the maximum number of transactions per GetTransactions request
+const MaxTransactionsPageSize = 50
+
+// TransactionCursor is the position to resume an incremental sync from
+//
+// Store it after a complete walk and pass it to the next iterator to only get newer transactions
+type TransactionCursor struct {
+	AfterBlockHash string `json:"afterBlockHash,omitempty"`
+	AfterHeight    int64  `json:"afterHeight,omitempty"`
+}
+
+// TransactionIterator walks every page of GetTransactions, one transaction at a time
+//
+//	iterator := bitindex.NewTransactionIterator(ctx, client, &bitindex.GetTransactionsRequest{Addresses: addresses}, 0)
+//	for iterator.Next() {
+//		tx := iterator.Transaction()
+//	}
+//	if err := iterator.Err(); err != nil {
+//		...
+//	}
+//	cursor := iterator.Cursor() // resume from here next time
+type TransactionIterator struct {
+	current   *Transaction           // is the current transaction
+	cursor    TransactionCursor      // is the starting cursor
+	finished  bool                   // is set when Next() returned false after the last page
+	fromIndex int64                  // is the index of the next page
+	highest   TransactionCursor      // is the highest confirmed block seen
+	page      []Transaction          // is the current page
+	pageSize  int64                  // is the number of transactions per request
+	pager     *pager                 // walks the pages (skips the txids already returned)
+	request   GetTransactionsRequest // is the request (copied)
+	service   AddressService         // is the client (or any AddressService)
+}
+
+// NewTransactionIterator will create an iterator for the request
+//
+// The page size is capped at MaxTransactionsPageSize (0 uses the maximum). The request
+// AfterHeight/AfterBlockHash (or a cursor set with WithCursor()) limits the walk to newer transactions
+func NewTransactionIterator(ctx context.Context, service AddressService, request *GetTransactionsRequest, pageSize int) *TransactionIterator {
+	if pageSize <= 0 || pageSize > MaxTransactionsPageSize {
+		pageSize = MaxTransactionsPageSize
+	}
+
+	iterator := &TransactionIterator{
+		pageSize: int64(pageSize),
+		service:  service,
+	}
+	iterator.pager = newPager(ctx, iterator.fetch)
+	if request != nil {
+		iterator.request = *request
+		iterator.request.Addresses = append([]string(nil), request.Addresses...)
+	}
+
+	// The starting cursor
+	iterator.cursor.AfterBlockHash = iterator.request.AfterBlockHash
+	iterator.cursor.AfterHeight, _ = strconv.ParseInt(iterator.request.AfterHeight, 10, 64)
+	iterator.highest = iterator.cursor
+	return iterator
+}
+
+// WithCursor will resume from the cursor (call before the first Next())
+func (t *TransactionIterator) WithCursor(cursor TransactionCursor) *TransactionIterator {
+	t.cursor, t.highest = cursor, cursor
+	t.request.AfterBlockHash = cursor.AfterBlockHash
+	t.request.AfterHeight = ""
+	if cursor.AfterHeight > 0 {
+		t.request.AfterHeight = strconv.FormatInt(cursor.AfterHeight, 10)
+	}
+	return t
+}
+
+// Next will advance to the next transaction, returns false when done or on error (see Err())
+func (t *TransactionIterator) Next() bool {
+	index, ok := t.pager.next()
+	if !ok {
+		t.current, t.finished = nil, t.pager.err == nil
+		return false
+	}
+	t.current = &t.page[index]
+	if t.current.BlockHeight > t.highest.AfterHeight {
+		t.highest = TransactionCursor{AfterBlockHash: t.current.BlockHash, AfterHeight: t.current.BlockHeight}
+	}
+	return true
+}
+
+// fetch will get the next page
+func (t *TransactionIterator) fetch(ctx context.Context) (txIDs []string, last bool, err error) {
+	request := t.request
+	request.FromIndex = t.fromIndex
+	request.ToIndex = t.fromIndex + t.pageSize
+
+	var response *GetTransactionsResponse
+	if response, err = t.service.GetTransactionsWithContext(ctx, &request); err != nil {
+		return
+	}
+
+	t.page = response.Items
+	for i := range t.page {
+		txIDs = append(txIDs, t.page[i].TxID)
+	}
+
+	// Move to the next page (guard against a missing "to")
+	next := response.To
+	if next <= t.fromIndex {
+		next = t.fromIndex + int64(len(response.Items))
+	}
+	t.fromIndex = next
+	last = len(response.Items) == 0 || t.fromIndex >= response.TotalItems
+	return
+}
+
+// Transaction returns the current transaction
+func (t *TransactionIterator) Transaction() *Transaction {
+	return t.current
+}
+
+// Err returns the error that stopped the iterator (nil when all pages were read)
+func (t *TransactionIterator) Err() error {
+	return t.pager.err
+}
+
+// Cursor returns the position to resume from
+//
+// After a complete walk (Next() returned false without an error) it is the highest confirmed
+// block seen, otherwise it is the starting cursor so no transactions are skipped on the next sync
+func (t *TransactionIterator) Cursor() TransactionCursor {
+	if t.pager.err != nil || !t.finished {
+		return t.cursor
+	}
+	return t.highest
+}
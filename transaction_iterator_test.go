@@ -0,0 +1,161 @@
+package bitindex_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/mrz1836/go-bitindex"
+	"github.com/mrz1836/go-bitindex/bitindextest"
+)
+
+// addHistory adds count transactions (tx-{from}...) at heights 100+n that pay the address
+func addHistory(server *bitindextest.Server, address string, from, count int) {
+	for i := from; i < from+count; i++ {
+		tx := bitindextest.NewTransaction(fmt.Sprintf("tx-%d", i), int64(100+i), nil, []bitindextest.Output{{Address: address, Satoshis: 1000}})
+		tx.BlockHash = fmt.Sprintf("hash-%d", 100+i)
+		server.AddTransaction(tx)
+	}
+}
+
+// TestTransactionIterator tests walking every page
+func TestTransactionIterator(t *testing.T) {
+	t.Parallel()
+
+	server, client := newFakeServer(t, nil)
+	addHistory(server, "1abc", 1, 7)
+
+	iterator := bitindex.NewTransactionIterator(context.Background(), client, &bitindex.GetTransactionsRequest{Addresses: []string{"1abc", "1def"}}, 3)
+	var txIDs []string
+	for iterator.Next() {
+		txIDs = append(txIDs, iterator.Transaction().TxID)
+	}
+	if err := iterator.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(txIDs) != 7 || txIDs[0] != "tx-7" || txIDs[6] != "tx-1" {
+		t.Fatalf("expected 7 transactions (newest first) got: %v", txIDs)
+	}
+	requests := transactionRequests(server)
+	if len(requests) != 3 || requests[2].FromIndex != 6 || requests[2].ToIndex != 9 {
+		t.Fatalf("expected 3 pages got: %+v", requests)
+	}
+	if requests[0].Address != "1abc,1def" {
+		t.Fatalf("expected the addresses to be joined got: %s", requests[0].Address)
+	}
+	if cursor := iterator.Cursor(); cursor.AfterHeight != 107 || cursor.AfterBlockHash != "hash-107" {
+		t.Fatalf("expected the cursor at 107 got: %+v", cursor)
+	}
+	if iterator.Next() || iterator.Transaction() != nil {
+		t.Fatal("expected the iterator to stay done")
+	}
+}
+
+// TestTransactionIterator_Cursor tests resuming from a cursor
+func TestTransactionIterator_Cursor(t *testing.T) {
+	t.Parallel()
+
+	server, client := newFakeServer(t, nil)
+	addHistory(server, "1abc", 1, 7)
+
+	// Nothing new
+	cursor := bitindex.TransactionCursor{AfterHeight: 107}
+	iterator := bitindex.NewTransactionIterator(context.Background(), client, &bitindex.GetTransactionsRequest{Address: "1abc"}, 0).WithCursor(cursor)
+	if iterator.Next() {
+		t.Fatalf("expected no transactions got: %s", iterator.Transaction().TxID)
+	} else if iterator.Err() != nil || iterator.Cursor() != cursor {
+		t.Fatalf("expected the same cursor got: %+v %v", iterator.Cursor(), iterator.Err())
+	}
+	if requests := transactionRequests(server); requests[0].AfterHeight != "107" || requests[0].ToIndex != bitindex.MaxTransactionsPageSize {
+		t.Fatalf("unexpected request: %+v", requests[0])
+	}
+
+	// Two new transactions
+	addHistory(server, "1abc", 8, 2)
+
+	iterator = bitindex.NewTransactionIterator(context.Background(), client, &bitindex.GetTransactionsRequest{Address: "1abc"}, 1).WithCursor(cursor)
+	count := 0
+	for iterator.Next() {
+		count++
+
+		// Not finished, the cursor does not move
+		if iterator.Cursor() != cursor {
+			t.Fatalf("expected the starting cursor until done got: %+v", iterator.Cursor())
+		}
+	}
+	if count != 2 || iterator.Cursor().AfterHeight != 109 {
+		t.Fatalf("expected 2 new transactions and the cursor at 109 got: %d %+v", count, iterator.Cursor())
+	}
+}
+
+// TestTransactionIterator_ShiftedPages tests that new transactions between pages are not returned twice
+func TestTransactionIterator_ShiftedPages(t *testing.T) {
+	t.Parallel()
+
+	server, client := newFakeServer(t, nil)
+	addHistory(server, "1abc", 1, 4)
+
+	iterator := bitindex.NewTransactionIterator(context.Background(), client, &bitindex.GetTransactionsRequest{Address: "1abc"}, 2)
+	seen := make(map[string]int)
+	for iterator.Next() {
+		seen[iterator.Transaction().TxID]++
+
+		// A new (unconfirmed) transaction arrives after the first page
+		if len(seen) == 2 {
+			server.AddTransaction(bitindextest.NewTransaction("tx-new", 0, nil, []bitindextest.Output{{Address: "1abc", Satoshis: 1000}}))
+		}
+	}
+	if err := iterator.Err(); err != nil {
+		t.Fatal(err)
+	}
+	for txID, count := range seen {
+		if count > 1 {
+			t.Fatalf("expected %s once got: %d", txID, count)
+		}
+	}
+	if len(seen) != 4 {
+		t.Fatalf("expected the 4 original transactions got: %v", seen)
+	}
+}
+
+// TestTransactionIterator_Errors tests the errors and context cancellation
+func TestTransactionIterator_Errors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("api error", func(t *testing.T) {
+		server, client := newFakeServer(t, nil)
+		server.FailNext(http.StatusBadRequest)
+
+		iterator := bitindex.NewTransactionIterator(context.Background(), client, &bitindex.GetTransactionsRequest{Address: "bad"}, 10)
+		if iterator.Next() {
+			t.Fatal("expected no transactions")
+		}
+		if !errors.Is(iterator.Err(), bitindex.ErrBadRequest) {
+			t.Fatalf("expected ErrBadRequest got: %v", iterator.Err())
+		}
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		server, client := newFakeServer(t, nil)
+		addHistory(server, "1abc", 1, 5)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		iterator := bitindex.NewTransactionIterator(ctx, client, &bitindex.GetTransactionsRequest{Address: "1abc"}, 2)
+		if !iterator.Next() {
+			t.Fatal(iterator.Err())
+		}
+		cancel()
+		if iterator.Next() {
+			t.Fatal("expected the iterator to stop")
+		}
+		if !errors.Is(iterator.Err(), context.Canceled) {
+			t.Fatalf("expected context.Canceled got: %v", iterator.Err())
+		}
+		if iterator.Cursor() != (bitindex.TransactionCursor{}) {
+			t.Fatalf("expected the starting cursor got: %+v", iterator.Cursor())
+		}
+	})
+}
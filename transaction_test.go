@@ -4,13 +4,8 @@ import "testing"
 
 // TestClient_GetTransaction tests the GetTransaction()
 func TestClient_GetTransaction(t *testing.T) {
-	// Skip this test in short mode (not needed)
-	if testing.Short() {
-		t.Skip("skipping testing in short mode")
-	}
-
-	// Create a new client object to handle your queries (supply an API Key)
-	client, err := NewClient(testAPIKey, NetworkMain, nil)
+	// Create a new client object to handle your queries (replays the cassette if recorded)
+	client, err := newCassetteClient(t, NetworkMain)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -35,13 +30,8 @@ func TestClient_GetTransaction(t *testing.T) {
 
 // TestClient_GetTransactionRaw tests the GetTransactionRaw()
 func TestClient_GetTransactionRaw(t *testing.T) {
-	// Skip this test in short mode (not needed)
-	if testing.Short() {
-		t.Skip("skipping testing in short mode")
-	}
-
-	// Create a new client object to handle your queries (supply an API Key)
-	client, err := NewClient(testAPIKey, NetworkMain, nil)
+	// Create a new client object to handle your queries (replays the cassette if recorded)
+	client, err := newCassetteClient(t, NetworkMain)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -66,13 +56,8 @@ func TestClient_GetTransactionRaw(t *testing.T) {
 
 // TestClient_SendTransaction tests the SendTransaction()
 func TestClient_SendTransaction(t *testing.T) {
-	// Skip this test in short mode (not needed)
-	if testing.Short() {
-		t.Skip("skipping testing in short mode")
-	}
-
-	// Create a new client object to handle your queries (supply an API Key)
-	client, err := NewClient(testAPIKey, NetworkMain, nil)
+	// Create a new client object to handle your queries (replays the cassette if recorded)
+	client, err := newCassetteClient(t, NetworkMain)
 	if err != nil {
 		t.Fatal(err)
 	}
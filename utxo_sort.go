@@ -0,0 +1,121 @@
+package bitindex
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// UTXOSortField is a field to sort UTXOs by
+type UTXOSortField string
+
+// UTXO sort fields
+const (
+	UTXOSortByConfirmations UTXOSortField = "confirmations"
+	UTXOSortByHeight        UTXOSortField = "height"
+	UTXOSortByTxID          UTXOSortField = "txid"
+	UTXOSortByValue         UTXOSortField = "value"
+)
+
+// SortDirection is the direction of a sort
+type SortDirection string
+
+// Sort directions
+const (
+	SortAscending  SortDirection = "asc"
+	SortDescending SortDirection = "desc"
+)
+
+// ErrInvalidSort is returned when a UTXO sort is not a known field and direction
+var ErrInvalidSort = errors.New("invalid sort")
+
+// UTXOSort is the sort for the UTXO requests in the api format "field:direction" (IE: value:desc)
+//
+// Create one with NewUTXOSort() or use a literal ("value:desc"), an empty sort is unsorted
+type UTXOSort string
+
+// NewUTXOSort will create the sort for the field and direction
+func NewUTXOSort(field UTXOSortField, direction SortDirection) UTXOSort {
+	return UTXOSort(string(field) + ":" + string(direction))
+}
+
+// Field returns the field of the sort
+func (s UTXOSort) Field() UTXOSortField {
+	return UTXOSortField(strings.SplitN(string(s), ":", 2)[0])
+}
+
+// Direction returns the direction of the sort (ascending if not set)
+func (s UTXOSort) Direction() SortDirection {
+	if parts := strings.SplitN(string(s), ":", 2); len(parts) == 2 {
+		return SortDirection(parts[1])
+	}
+	return SortAscending
+}
+
+// Validate will return ErrInvalidSort if the sort is not a known field and direction
+func (s UTXOSort) Validate() error {
+	if len(s) == 0 {
+		return nil
+	}
+	switch s.Field() {
+	case UTXOSortByConfirmations, UTXOSortByHeight, UTXOSortByTxID, UTXOSortByValue:
+	default:
+		return fmt.Errorf("%w: unknown field in %q", ErrInvalidSort, string(s))
+	}
+	if direction := s.Direction(); direction != SortAscending && direction != SortDescending {
+		return fmt.Errorf("%w: unknown direction in %q", ErrInvalidSort, string(s))
+	}
+	return nil
+}
+
+// endpoint will add the sort to the endpoint as an escaped query parameter (after validating it)
+func (s UTXOSort) endpoint(endpoint string) (string, error) {
+	if err := s.Validate(); err != nil {
+		return "", err
+	} else if len(s) == 0 {
+		return endpoint, nil
+	}
+	return endpoint + "?" + url.Values{"sort": []string{string(s)}}.Encode(), nil
+}
+
+// Sort will sort the UTXOs locally (stable, an empty sort does nothing)
+//
+// The UTXO requests already apply this, in case the server ignored the sort parameter
+func (u UnspentTransactions) Sort(sortBy UTXOSort) error {
+	if err := sortBy.Validate(); err != nil || len(sortBy) == 0 {
+		return err
+	}
+
+	field, descending := sortBy.Field(), sortBy.Direction() == SortDescending
+	less := func(a, b *UnspentTransaction) bool {
+		switch field {
+		case UTXOSortByConfirmations:
+			return a.Confirmations < b.Confirmations
+		case UTXOSortByHeight:
+			return a.Height < b.Height
+		case UTXOSortByTxID:
+			return a.TxID < b.TxID
+		default:
+			return a.satoshis() < b.satoshis()
+		}
+	}
+	sort.SliceStable(u, func(i, j int) bool {
+		if descending {
+			return less(&u[j], &u[i])
+		}
+		return less(&u[i], &u[j])
+	})
+	return nil
+}
+
+// satoshis returns the value of the UTXO from whichever amount field is set
+func (u *UnspentTransaction) satoshis() Satoshis {
+	if u.Amount != 0 {
+		return u.Amount
+	} else if u.Satoshis != 0 {
+		return u.Satoshis
+	}
+	return u.Value
+}
@@ -0,0 +1,123 @@
+package bitindex
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// TestUTXOSort_Validate tests the sort validation
+func TestUTXOSort_Validate(t *testing.T) {
+	t.Parallel()
+
+	var tests = []struct {
+		sort  UTXOSort
+		valid bool
+	}{
+		{"", true},
+		{"value", true},
+		{"value:desc", true},
+		{NewUTXOSort(UTXOSortByHeight, SortAscending), true},
+		{NewUTXOSort(UTXOSortByConfirmations, SortDescending), true},
+		{"txid:asc", true},
+		{"amount:desc", false},
+		{"value:down", false},
+		{"value:desc&limit=1", false},
+		{"Value:DESC", false},
+	}
+	for _, test := range tests {
+		if err := test.sort.Validate(); test.valid && err != nil {
+			t.Fatalf("%q: unexpected error: %v", test.sort, err)
+		} else if !test.valid && !errors.Is(err, ErrInvalidSort) {
+			t.Fatalf("%q: expected ErrInvalidSort got: %v", test.sort, err)
+		}
+	}
+
+	if sort := NewUTXOSort(UTXOSortByValue, SortDescending); sort != "value:desc" || sort.Field() != UTXOSortByValue || sort.Direction() != SortDescending {
+		t.Fatalf("unexpected sort: %q", sort)
+	}
+	if UTXOSort("height").Direction() != SortAscending {
+		t.Fatal("expected ascending by default")
+	}
+}
+
+// TestUnspentTransactions_Sort tests the local sort
+func TestUnspentTransactions_Sort(t *testing.T) {
+	t.Parallel()
+
+	utxos := UnspentTransactions{
+		{Amount: 500, Confirmations: 3, Height: 98, TxID: "b"},
+		{Confirmations: 0, Satoshis: 2000, TxID: "c"},
+		{Confirmations: 1, Height: 100, TxID: "a", Value: 1000},
+	}
+
+	var tests = []struct {
+		sort     UTXOSort
+		expected string
+	}{
+		{"value:desc", "cab"},
+		{"value:asc", "bac"},
+		{"txid", "abc"},
+		{"height:desc", "abc"},
+		{"confirmations:asc", "cab"},
+	}
+	for _, test := range tests {
+		if err := utxos.Sort(test.sort); err != nil {
+			t.Fatal(err)
+		}
+		if order := utxos[0].TxID + utxos[1].TxID + utxos[2].TxID; order != test.expected {
+			t.Fatalf("%q: expected %s got: %s", test.sort, test.expected, order)
+		}
+	}
+
+	if err := utxos.Sort("size:desc"); !errors.Is(err, ErrInvalidSort) {
+		t.Fatalf("expected ErrInvalidSort got: %v", err)
+	}
+}
+
+// TestClient_UnspentTransactions_Sort tests the sort is escaped and applied when the server ignores it
+func TestClient_UnspentTransactions_Sort(t *testing.T) {
+	t.Parallel()
+
+	var rawQuery string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawQuery = r.URL.RawQuery
+		_, _ = w.Write([]byte(`[{"txid":"small","satoshis":100},{"txid":"large","satoshis":900}]`))
+	}), nil)
+
+	utxos, err := client.GetUnspentTransactions(&GetUnspentTransactionsRequest{Address: "1abc", Sort: NewUTXOSort(UTXOSortByValue, SortDescending)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rawQuery != "sort=value%3Adesc" {
+		t.Fatalf("expected the escaped sort got: %s", rawQuery)
+	}
+	if utxos[0].TxID != "large" {
+		t.Fatalf("expected the largest first got: %+v", utxos)
+	}
+
+	if utxos, err = client.GetXpubUnspentTransactionsSorted("xpub123", "value:asc"); err != nil {
+		t.Fatal(err)
+	} else if rawQuery != "sort=value%3Aasc" || utxos[0].TxID != "small" {
+		t.Fatalf("expected the smallest first got: %s %+v", rawQuery, utxos)
+	}
+
+	// The deprecated string sort is the same request
+	legacySort := "value:asc"
+	if utxos, err = client.GetXpubUnspentTransactions("xpub123", legacySort); err != nil {
+		t.Fatal(err)
+	} else if rawQuery != "sort=value%3Aasc" || utxos[0].TxID != "small" {
+		t.Fatalf("expected the smallest first got: %s %+v", rawQuery, utxos)
+	}
+
+	// Invalid sorts are not sent
+	rawQuery = ""
+	if _, err = client.GetXpubUnspentTransactionsSorted("xpub123", "value:desc&limit=1"); !errors.Is(err, ErrInvalidSort) {
+		t.Fatalf("expected ErrInvalidSort got: %v", err)
+	} else if _, err = client.GetUnspentTransactions(&GetUnspentTransactionsRequest{Address: "1abc", Sort: "fee"}); !errors.Is(err, ErrInvalidSort) {
+		t.Fatalf("expected ErrInvalidSort got: %v", err)
+	}
+	if rawQuery != "" {
+		t.Fatalf("expected no request got: %s", rawQuery)
+	}
+}
@@ -1,8 +1,8 @@
 package bitindex
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"net/http"
 )
 
@@ -33,24 +33,36 @@ import (
 //
 // For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Webhook
 func (c *Client) GetWebhookConfig() (config *WebhookConfigResponse, err error) {
+	return c.GetWebhookConfigWithContext(context.Background())
+}
+
+// GetWebhookConfigWithContext is the same as GetWebhookConfig() but will be canceled when the given context is done
+//
+// For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Webhook
+func (c *Client) GetWebhookConfigWithContext(ctx context.Context) (config *WebhookConfigResponse, err error) {
 
 	// Create the request
-	var resp string
+	var resp *requestResponse
 	// /api/v3/network/webhook/endpoint
-	resp, err = c.Request("webhook/endpoint", http.MethodGet, nil)
+	resp, err = c.request(ctx, &apiRequest{
+		Endpoint:   "webhook/endpoint",
+		Idempotent: true,
+		Method:     http.MethodGet,
+		Name:       "GetWebhookConfig",
+	})
 	if err != nil {
 		return
 	}
 
-	// Process the response
-	config = new(WebhookConfigResponse)
-	if err = json.Unmarshal([]byte(resp), config); err != nil {
+	// Error from request?
+	if resp.StatusCode != http.StatusOK {
+		err = newAPIError(resp)
 		return
 	}
 
-	// Error from request?
-	if c.LastRequest.StatusCode != http.StatusOK {
-		err = fmt.Errorf("error: %s", config.ErrorMessage)
+	// Process the response
+	config = new(WebhookConfigResponse)
+	if err = json.Unmarshal(resp.Body, config); err != nil {
 		return
 	}
 	return
@@ -60,6 +72,13 @@ func (c *Client) GetWebhookConfig() (config *WebhookConfigResponse, err error) {
 //
 // For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Webhook
 func (c *Client) UpdateWebhookConfig(updateConfig *WebhookUpdateConfig) (config *WebhookConfigResponse, err error) {
+	return c.UpdateWebhookConfigWithContext(context.Background(), updateConfig)
+}
+
+// UpdateWebhookConfigWithContext is the same as UpdateWebhookConfig() but will be canceled when the given context is done
+//
+// For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Webhook
+func (c *Client) UpdateWebhookConfigWithContext(ctx context.Context, updateConfig *WebhookUpdateConfig) (config *WebhookConfigResponse, err error) {
 
 	// Marshall into JSON
 	var data []byte
@@ -69,22 +88,28 @@ func (c *Client) UpdateWebhookConfig(updateConfig *WebhookUpdateConfig) (config
 	}
 
 	// Create the request
-	var resp string
+	var resp *requestResponse
 	// /api/v3/network/webhook/endpoint
-	resp, err = c.Request("webhook/endpoint", http.MethodPut, data)
+	resp, err = c.request(ctx, &apiRequest{
+		Endpoint:   "webhook/endpoint",
+		Idempotent: true,
+		Method:     http.MethodPut,
+		Name:       "UpdateWebhookConfig",
+		Payload:    data,
+	})
 	if err != nil {
 		return
 	}
 
-	// Process the response
-	config = new(WebhookConfigResponse)
-	if err = json.Unmarshal([]byte(resp), config); err != nil {
+	// Error from request?
+	if resp.StatusCode != http.StatusOK {
+		err = newAPIError(resp)
 		return
 	}
 
-	// Error from request?
-	if c.LastRequest.StatusCode != http.StatusOK {
-		err = fmt.Errorf("error: %s", config.ErrorMessage)
+	// Process the response
+	config = new(WebhookConfigResponse)
+	if err = json.Unmarshal(resp.Body, config); err != nil {
 		return
 	}
 	return
@@ -94,28 +119,36 @@ func (c *Client) UpdateWebhookConfig(updateConfig *WebhookUpdateConfig) (config
 //
 // For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Webhook
 func (c *Client) GetMonitoredAddresses() (addresses MonitoredAddresses, err error) {
+	return c.GetMonitoredAddressesWithContext(context.Background())
+}
+
+// GetMonitoredAddressesWithContext is the same as GetMonitoredAddresses() but will be canceled when the given context is done
+//
+// For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Webhook
+func (c *Client) GetMonitoredAddressesWithContext(ctx context.Context) (addresses MonitoredAddresses, err error) {
 
 	// Create the request
-	var resp string
+	var resp *requestResponse
 	// /api/v3/network/webhook/monitored_addrs
-	resp, err = c.Request("webhook/monitored_addrs", http.MethodGet, nil)
+	resp, err = c.request(ctx, &apiRequest{
+		Endpoint:   "webhook/monitored_addrs",
+		Idempotent: true,
+		Method:     http.MethodGet,
+		Name:       "GetMonitoredAddresses",
+	})
 	if err != nil {
 		return
 	}
 
 	// Error from request?
-	if c.LastRequest.StatusCode != http.StatusOK {
-		var apiError APIInternalError
-		if err = json.Unmarshal([]byte(resp), &apiError); err != nil {
-			return
-		}
-		err = fmt.Errorf("error: %s", apiError.ErrorMessage)
+	if resp.StatusCode != http.StatusOK {
+		err = newAPIError(resp)
 		return
 	}
 
 	// Process the response
 	addresses = *new(MonitoredAddresses)
-	if err = json.Unmarshal([]byte(resp), &addresses); err != nil {
+	if err = json.Unmarshal(resp.Body, &addresses); err != nil {
 		return
 	}
 	return
@@ -125,6 +158,13 @@ func (c *Client) GetMonitoredAddresses() (addresses MonitoredAddresses, err erro
 //
 // For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Webhook
 func (c *Client) AddMonitoredAddresses(addAddresses *MonitoredAddresses) (addresses MonitoredAddresses, err error) {
+	return c.AddMonitoredAddressesWithContext(context.Background(), addAddresses)
+}
+
+// AddMonitoredAddressesWithContext is the same as AddMonitoredAddresses() but will be canceled when the given context is done
+//
+// For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Webhook
+func (c *Client) AddMonitoredAddressesWithContext(ctx context.Context, addAddresses *MonitoredAddresses) (addresses MonitoredAddresses, err error) {
 
 	// Marshall into JSON
 	var data []byte
@@ -134,26 +174,28 @@ func (c *Client) AddMonitoredAddresses(addAddresses *MonitoredAddresses) (addres
 	}
 
 	// Create the request
-	var resp string
+	var resp *requestResponse
 	// /api/v3/network/webhook/monitored_addrs
-	resp, err = c.Request("webhook/monitored_addrs", http.MethodPut, data)
+	resp, err = c.request(ctx, &apiRequest{
+		Endpoint:   "webhook/monitored_addrs",
+		Idempotent: true,
+		Method:     http.MethodPut,
+		Name:       "AddMonitoredAddresses",
+		Payload:    data,
+	})
 	if err != nil {
 		return
 	}
 
 	// Error from request?
-	if c.LastRequest.StatusCode != http.StatusOK {
-		var apiError APIInternalError
-		if err = json.Unmarshal([]byte(resp), &apiError); err != nil {
-			return
-		}
-		err = fmt.Errorf("error: %s", apiError.ErrorMessage)
+	if resp.StatusCode != http.StatusOK {
+		err = newAPIError(resp)
 		return
 	}
 
 	// Process the response
 	addresses = *new(MonitoredAddresses)
-	if err = json.Unmarshal([]byte(resp), &addresses); err != nil {
+	if err = json.Unmarshal(resp.Body, &addresses); err != nil {
 		return
 	}
 	return
@@ -4,13 +4,8 @@ import "testing"
 
 // TestClient_GetWebhookConfig tests the GetWebhookConfig()
 func TestClient_GetWebhookConfig(t *testing.T) {
-	// Skip this test in short mode (not needed)
-	if testing.Short() {
-		t.Skip("skipping testing in short mode")
-	}
-
-	// Create a new client object to handle your queries (supply an API Key)
-	client, err := NewClient(testAPIKey, NetworkMain, nil)
+	// Create a new client object to handle your queries (replays the cassette if recorded)
+	client, err := newCassetteClient(t, NetworkMain)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -28,13 +23,8 @@ func TestClient_GetWebhookConfig(t *testing.T) {
 
 // TestClient_GetMonitoredAddresses tests the GetMonitoredAddresses()
 func TestClient_GetMonitoredAddresses(t *testing.T) {
-	// Skip this test in short mode (not needed)
-	if testing.Short() {
-		t.Skip("skipping testing in short mode")
-	}
-
-	// Create a new client object to handle your queries (supply an API Key)
-	client, err := NewClient(testAPIKey, NetworkMain, nil)
+	// Create a new client object to handle your queries (replays the cassette if recorded)
+	client, err := newCassetteClient(t, NetworkMain)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -52,13 +42,8 @@ func TestClient_GetMonitoredAddresses(t *testing.T) {
 
 // TestClient_UpdateWebhookConfig tests the UpdateWebhookConfig()
 func TestClient_UpdateWebhookConfig(t *testing.T) {
-	// Skip this test in short mode (not needed)
-	if testing.Short() {
-		t.Skip("skipping testing in short mode")
-	}
-
-	// Create a new client object to handle your queries (supply an API Key)
-	client, err := NewClient(testAPIKey, NetworkMain, nil)
+	// Create a new client object to handle your queries (replays the cassette if recorded)
+	client, err := newCassetteClient(t, NetworkMain)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -86,13 +71,8 @@ func TestClient_UpdateWebhookConfig(t *testing.T) {
 
 // TestClient_AddMonitoredAddresses tests the AddMonitoredAddresses()
 func TestClient_AddMonitoredAddresses(t *testing.T) {
-	// Skip this test in short mode (not needed)
-	if testing.Short() {
-		t.Skip("skipping testing in short mode")
-	}
-
-	// Create a new client object to handle your queries (supply an API Key)
-	client, err := NewClient(testAPIKey, NetworkMain, nil)
+	// Create a new client object to handle your queries (replays the cassette if recorded)
+	client, err := newCassetteClient(t, NetworkMain)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1,18 +1,23 @@
 package bitindex
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"net/url"
-	"strconv"
-	"strings"
 )
 
 // GetXpubNextAddress this endpoint that gets the next address for a xpub and reserve if given.
 //
 // For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Xpub
 func (c *Client) GetXpubNextAddress(xPub string, reserveTimeSeconds int) (addresses XpubAddresses, err error) {
+	return c.GetXpubNextAddressWithContext(context.Background(), xPub, reserveTimeSeconds)
+}
+
+// GetXpubNextAddressWithContext is the same as GetXpubNextAddress() but will be canceled when the given context is done
+//
+// For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Xpub
+func (c *Client) GetXpubNextAddressWithContext(ctx context.Context, xPub string, reserveTimeSeconds int) (addresses XpubAddresses, err error) {
 
 	endpoint := "xpub/" + xPub + "/addrs/next"
 	if reserveTimeSeconds > 0 {
@@ -20,26 +25,27 @@ func (c *Client) GetXpubNextAddress(xPub string, reserveTimeSeconds int) (addres
 	}
 
 	// Create the request
-	var resp string
-	// /api/v3/network/xpub/xpub/addrs/next
-	resp, err = c.Request(endpoint, http.MethodGet, nil)
+	var resp *requestResponse
+	// /api/v3/network/xpub/xpub/addrs/next (a reservation is a side effect, not safe to retry)
+	resp, err = c.request(ctx, &apiRequest{
+		Endpoint:   endpoint,
+		Idempotent: reserveTimeSeconds <= 0,
+		Method:     http.MethodGet,
+		Name:       "GetXpubNextAddress",
+	})
 	if err != nil {
 		return
 	}
 
 	// Error from request?
-	if c.LastRequest.StatusCode != http.StatusOK {
-		var apiError APIInternalError
-		if err = json.Unmarshal([]byte(resp), &apiError); err != nil {
-			return
-		}
-		err = fmt.Errorf("error: %s", apiError.ErrorMessage)
+	if resp.StatusCode != http.StatusOK {
+		err = newAPIError(resp)
 		return
 	}
 
 	// Process the response
 	addresses = *new(XpubAddresses)
-	if err = json.Unmarshal([]byte(resp), &addresses); err != nil {
+	if err = json.Unmarshal(resp.Body, &addresses); err != nil {
 		return
 	}
 	return
@@ -49,53 +55,67 @@ func (c *Client) GetXpubNextAddress(xPub string, reserveTimeSeconds int) (addres
 
 // GetXpubAddresses this endpoint will return addresses for an xpub given the parameters.
 //
+// Deprecated: use GetXpubAddressesPage() with the typed GetXpubAddressesOptions (validated).
+//
 // For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Xpub
 func (c *Client) GetXpubAddresses(xPub string, offset, limit int, order, filterByAddress string) (addresses XpubAddresses, err error) {
+	return c.GetXpubAddressesWithContext(context.Background(), xPub, offset, limit, order, filterByAddress)
+}
 
-	endpoint := "xpub/" + xPub + "/addrs"
+// GetXpubAddressesWithContext is the same as GetXpubAddresses() but will be canceled when the given context is done
+//
+// Deprecated: use GetXpubAddressesPageWithContext() with the typed GetXpubAddressesOptions (validated).
+//
+// For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Xpub
+func (c *Client) GetXpubAddressesWithContext(ctx context.Context, xPub string, offset, limit int, order, filterByAddress string) (addresses XpubAddresses, err error) {
+	return c.GetXpubAddressesPageWithContext(ctx, xPub, &GetXpubAddressesOptions{
+		Address: filterByAddress,
+		Limit:   limit,
+		Offset:  offset,
+		Order:   SortDirection(order),
+	})
+}
 
-	// Set the params
-	params := url.Values{}
-	if offset > 0 {
-		params.Add("offset", strconv.Itoa(offset))
-	}
-	if limit > 0 {
-		params.Add("limit", strconv.Itoa(limit))
-	}
-	if len(order) > 0 {
-		params.Add("order", strings.ToLower(order))
-	}
-	if len(filterByAddress) > 0 {
-		params.Add("address", strings.TrimSpace(filterByAddress))
-	}
+// GetXpubAddressesPage this endpoint will return addresses for an xpub given the options (nil uses the api defaults).
+//
+// For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Xpub
+func (c *Client) GetXpubAddressesPage(xPub string, options *GetXpubAddressesOptions) (addresses XpubAddresses, err error) {
+	return c.GetXpubAddressesPageWithContext(context.Background(), xPub, options)
+}
+
+// GetXpubAddressesPageWithContext is the same as GetXpubAddressesPage() but will be canceled when the given context is done
+//
+// For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Xpub
+func (c *Client) GetXpubAddressesPageWithContext(ctx context.Context, xPub string, options *GetXpubAddressesOptions) (addresses XpubAddresses, err error) {
 
-	// Set the dynamic query string
-	queryString := params.Encode()
-	if len(queryString) > 0 {
-		endpoint = endpoint + "?" + queryString
+	// Set the params (validated & escaped)
+	var endpoint string
+	if endpoint, err = options.endpoint("xpub/" + xPub + "/addrs"); err != nil {
+		return
 	}
 
 	// Create the request
-	var resp string
+	var resp *requestResponse
 	// /api/v3/network/xpub/xpub/addrs
-	resp, err = c.Request(endpoint, http.MethodGet, nil)
+	resp, err = c.request(ctx, &apiRequest{
+		Endpoint:   endpoint,
+		Idempotent: true,
+		Method:     http.MethodGet,
+		Name:       "GetXpubAddresses",
+	})
 	if err != nil {
 		return
 	}
 
 	// Error from request?
-	if c.LastRequest.StatusCode != http.StatusOK {
-		var apiError APIInternalError
-		if err = json.Unmarshal([]byte(resp), &apiError); err != nil {
-			return
-		}
-		err = fmt.Errorf("error: %s", apiError.ErrorMessage)
+	if resp.StatusCode != http.StatusOK {
+		err = newAPIError(resp)
 		return
 	}
 
 	// Process the response
 	addresses = *new(XpubAddresses)
-	if err = json.Unmarshal([]byte(resp), &addresses); err != nil {
+	if err = json.Unmarshal(resp.Body, &addresses); err != nil {
 		return
 	}
 	return
@@ -105,24 +125,36 @@ func (c *Client) GetXpubAddresses(xPub string, offset, limit int, order, filterB
 //
 // For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Xpub
 func (c *Client) GetXpubBalance(xPub string) (balance *XpubBalance, err error) {
+	return c.GetXpubBalanceWithContext(context.Background(), xPub)
+}
+
+// GetXpubBalanceWithContext is the same as GetXpubBalance() but will be canceled when the given context is done
+//
+// For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Xpub
+func (c *Client) GetXpubBalanceWithContext(ctx context.Context, xPub string) (balance *XpubBalance, err error) {
 
 	// Create the request
-	var resp string
+	var resp *requestResponse
 	// /api/v3/network/xpub/xpub/status
-	resp, err = c.Request("xpub/"+xPub+"/status", http.MethodGet, nil)
+	resp, err = c.request(ctx, &apiRequest{
+		Endpoint:   "xpub/" + xPub + "/status",
+		Idempotent: true,
+		Method:     http.MethodGet,
+		Name:       "GetXpubBalance",
+	})
 	if err != nil {
 		return
 	}
 
-	// Process the response
-	balance = new(XpubBalance)
-	if err = json.Unmarshal([]byte(resp), balance); err != nil {
+	// Error from request?
+	if resp.StatusCode != http.StatusOK {
+		err = newAPIError(resp)
 		return
 	}
 
-	// Error from request?
-	if c.LastRequest.StatusCode != http.StatusOK {
-		err = fmt.Errorf("error: %s", balance.ErrorMessage)
+	// Process the response
+	balance = new(XpubBalance)
+	if err = json.Unmarshal(resp.Body, balance); err != nil {
 		return
 	}
 	return
@@ -130,37 +162,67 @@ func (c *Client) GetXpubBalance(xPub string) (balance *XpubBalance, err error) {
 
 // GetXpubUnspentTransactions this endpoint retrieves list of unspent transactions for a xpub address.
 //
+// Deprecated: use GetXpubUnspentTransactionsSorted() with a typed UTXOSort (see NewUTXOSort()).
+//
 // For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Xpub
 func (c *Client) GetXpubUnspentTransactions(xPub, sort string) (transactions UnspentTransactions, err error) {
+	return c.GetXpubUnspentTransactionsWithContext(context.Background(), xPub, sort)
+}
 
-	endpoint := "xpub/" + xPub + "/utxo"
-	if len(sort) > 0 {
-		endpoint = endpoint + "?sort=" + sort
+// GetXpubUnspentTransactionsWithContext is the same as GetXpubUnspentTransactions() but will be canceled when the given context is done
+//
+// Deprecated: use GetXpubUnspentTransactionsSortedWithContext() with a typed UTXOSort (see NewUTXOSort()).
+//
+// For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Xpub
+func (c *Client) GetXpubUnspentTransactionsWithContext(ctx context.Context, xPub, sort string) (transactions UnspentTransactions, err error) {
+	return c.GetXpubUnspentTransactionsSortedWithContext(ctx, xPub, UTXOSort(sort))
+}
+
+// GetXpubUnspentTransactionsSorted this endpoint retrieves list of unspent transactions for a xpub address (sorted).
+//
+// For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Xpub
+func (c *Client) GetXpubUnspentTransactionsSorted(xPub string, sort UTXOSort) (transactions UnspentTransactions, err error) {
+	return c.GetXpubUnspentTransactionsSortedWithContext(context.Background(), xPub, sort)
+}
+
+// GetXpubUnspentTransactionsSortedWithContext is the same as GetXpubUnspentTransactionsSorted() but will be canceled when the given context is done
+//
+// For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Xpub
+func (c *Client) GetXpubUnspentTransactionsSortedWithContext(ctx context.Context, xPub string, sort UTXOSort) (transactions UnspentTransactions, err error) {
+
+	// Do we have a sort (validated & escaped)
+	var endpoint string
+	if endpoint, err = sort.endpoint("xpub/" + xPub + "/utxo"); err != nil {
+		return
 	}
 
 	// Create the request
-	var resp string
+	var resp *requestResponse
 	// /api/v3/network/xpub/xpub/utxo
-	resp, err = c.Request(endpoint, http.MethodGet, nil)
+	resp, err = c.request(ctx, &apiRequest{
+		Endpoint:   endpoint,
+		Idempotent: true,
+		Method:     http.MethodGet,
+		Name:       "GetXpubUnspentTransactions",
+	})
 	if err != nil {
 		return
 	}
 
 	// Error from request?
-	if c.LastRequest.StatusCode != http.StatusOK {
-		var apiError APIInternalError
-		if err = json.Unmarshal([]byte(resp), &apiError); err != nil {
-			return
-		}
-		err = fmt.Errorf("error: %s", apiError.ErrorMessage)
+	if resp.StatusCode != http.StatusOK {
+		err = newAPIError(resp)
 		return
 	}
 
 	// Process the response
 	transactions = *new(UnspentTransactions)
-	if err = json.Unmarshal([]byte(resp), &transactions); err != nil {
+	if err = json.Unmarshal(resp.Body, &transactions); err != nil {
 		return
 	}
+
+	// Sort locally (in case the server ignored the sort)
+	err = transactions.Sort(sort)
 	return
 }
 
@@ -168,28 +230,36 @@ func (c *Client) GetXpubUnspentTransactions(xPub, sort string) (transactions Uns
 //
 // For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Xpub
 func (c *Client) GetXpubTransactions(xPub string) (transactions XpubAddresses, err error) {
+	return c.GetXpubTransactionsWithContext(context.Background(), xPub)
+}
+
+// GetXpubTransactionsWithContext is the same as GetXpubTransactions() but will be canceled when the given context is done
+//
+// For more information: https://www.bitindex.network/developers/api-documentation-v3.html#Xpub
+func (c *Client) GetXpubTransactionsWithContext(ctx context.Context, xPub string) (transactions XpubAddresses, err error) {
 
 	// Create the request
-	var resp string
+	var resp *requestResponse
 	// /api/v3/network/xpub/xpub/txs
-	resp, err = c.Request("xpub/"+xPub+"/txs", http.MethodGet, nil)
+	resp, err = c.request(ctx, &apiRequest{
+		Endpoint:   "xpub/" + xPub + "/txs",
+		Idempotent: true,
+		Method:     http.MethodGet,
+		Name:       "GetXpubTransactions",
+	})
 	if err != nil {
 		return
 	}
 
 	// Error from request?
-	if c.LastRequest.StatusCode != http.StatusOK {
-		var apiError APIInternalError
-		if err = json.Unmarshal([]byte(resp), &apiError); err != nil {
-			return
-		}
-		err = fmt.Errorf("error: %s", apiError.ErrorMessage)
+	if resp.StatusCode != http.StatusOK {
+		err = newAPIError(resp)
 		return
 	}
 
 	// Process the response
 	transactions = *new(XpubAddresses)
-	if err = json.Unmarshal([]byte(resp), &transactions); err != nil {
+	if err = json.Unmarshal(resp.Body, &transactions); err != nil {
 		return
 	}
 	return
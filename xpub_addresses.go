@@ -0,0 +1,147 @@
+package bitindex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// MaxXpubAddressesLimit is the maximum number of addresses per GetXpubAddressesPage request
+const MaxXpubAddressesLimit = 1000
+
+// ErrInvalidLimit is returned when a limit (or offset) is out of bounds
+var ErrInvalidLimit = errors.New("invalid limit")
+
+// GetXpubAddressesOptions are the options for GetXpubAddressesPage() (nil or empty uses the api defaults)
+type GetXpubAddressesOptions struct {
+	Address string        // is the address to filter by
+	Limit   int           // is the number of addresses (0 uses the api default, at most MaxXpubAddressesLimit)
+	Offset  int           // is the number of addresses to skip
+	Order   SortDirection // is the order of the addresses (SortAscending or SortDescending)
+}
+
+// Validate will return ErrInvalidLimit or ErrInvalidSort if the options are out of bounds
+func (o *GetXpubAddressesOptions) Validate() error {
+	if o == nil {
+		return nil
+	}
+	if o.Limit < 0 || o.Limit > MaxXpubAddressesLimit {
+		return fmt.Errorf("%w: %d is not between 0 and %d", ErrInvalidLimit, o.Limit, MaxXpubAddressesLimit)
+	} else if o.Offset < 0 {
+		return fmt.Errorf("%w: negative offset %d", ErrInvalidLimit, o.Offset)
+	}
+	switch SortDirection(strings.ToLower(string(o.Order))) {
+	case "", SortAscending, SortDescending:
+	default:
+		return fmt.Errorf("%w: unknown order %q", ErrInvalidSort, string(o.Order))
+	}
+	return nil
+}
+
+// endpoint will add the options to the endpoint as query parameters (after validating them)
+func (o *GetXpubAddressesOptions) endpoint(endpoint string) (string, error) {
+	if err := o.Validate(); err != nil {
+		return "", err
+	} else if o == nil {
+		return endpoint, nil
+	}
+
+	// Set the params
+	params := url.Values{}
+	if o.Offset > 0 {
+		params.Add("offset", strconv.Itoa(o.Offset))
+	}
+	if o.Limit > 0 {
+		params.Add("limit", strconv.Itoa(o.Limit))
+	}
+	if len(o.Order) > 0 {
+		params.Add("order", strings.ToLower(string(o.Order)))
+	}
+	if address := strings.TrimSpace(o.Address); len(address) > 0 {
+		params.Add("address", address)
+	}
+
+	// Set the dynamic query string
+	if queryString := params.Encode(); len(queryString) > 0 {
+		endpoint = endpoint + "?" + queryString
+	}
+	return endpoint, nil
+}
+
+// XpubAddressIterator walks every page of GetXpubAddressesPage, one address at a time
+//
+//	iterator := bitindex.NewXpubAddressIterator(ctx, client, xPub, nil)
+//	for iterator.Next() {
+//		address := iterator.Address()
+//	}
+//	if err := iterator.Err(); err != nil {
+//		...
+//	}
+type XpubAddressIterator struct {
+	current *XPubAddress            // is the current address
+	options GetXpubAddressesOptions // are the options (the offset moves with each page)
+	page    XpubAddresses           // is the current page
+	pager   *pager                  // walks the pages (skips the addresses already returned)
+	service XpubService             // is the client (or any XpubService)
+	xPub    string                  // is the xpub
+}
+
+// NewXpubAddressIterator will create an iterator for the xpub addresses
+//
+// The options Limit is the page size (0 uses MaxXpubAddressesLimit), the walk starts at the Offset.
+// The walk stops at a short page or at a page without a new address (IE: the server ignored the offset)
+func NewXpubAddressIterator(ctx context.Context, service XpubService, xPub string, options *GetXpubAddressesOptions) *XpubAddressIterator {
+	iterator := &XpubAddressIterator{
+		service: service,
+		xPub:    xPub,
+	}
+	if options != nil {
+		iterator.options = *options
+	}
+	iterator.pager = newPager(ctx, iterator.fetch)
+	iterator.pager.err = iterator.options.Validate()
+	if iterator.options.Limit == 0 {
+		iterator.options.Limit = MaxXpubAddressesLimit
+	}
+	return iterator
+}
+
+// Next will advance to the next address, returns false when done or on error (see Err())
+func (x *XpubAddressIterator) Next() bool {
+	index, ok := x.pager.next()
+	if !ok {
+		x.current = nil
+		return false
+	}
+	x.current = &x.page[index]
+	return true
+}
+
+// fetch will get the next page
+func (x *XpubAddressIterator) fetch(ctx context.Context) (addresses []string, last bool, err error) {
+	options := x.options
+	if x.page, err = x.service.GetXpubAddressesPageWithContext(ctx, x.xPub, &options); err != nil {
+		return
+	}
+	for i := range x.page {
+		addresses = append(addresses, x.page[i].Address)
+	}
+
+	// A short page is the last page
+	x.options.Offset += len(x.page)
+	last = len(x.page) < x.options.Limit
+	return
+}
+
+// Address returns the current address
+func (x *XpubAddressIterator) Address() *XPubAddress {
+	return x.current
+}
+
+// Err returns the error that stopped the iterator (nil when all pages were read)
+func (x *XpubAddressIterator) Err() error {
+	return x.pager.err
+}
@@ -0,0 +1,126 @@
+package bitindex_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mrz1836/go-bitindex"
+	"github.com/mrz1836/go-bitindex/bitindextest"
+)
+
+// addressQueries returns the query strings of the xpub/{xpub}/addrs requests
+func addressQueries(server *bitindextest.Server, xPub string) (queries []string) {
+	for _, request := range server.Requests() {
+		if request.Path == "xpub/"+xPub+"/addrs" {
+			queries = append(queries, request.RawQuery)
+		}
+	}
+	return
+}
+
+// TestClient_GetXpubAddressesPage tests the query string of the options
+func TestClient_GetXpubAddressesPage(t *testing.T) {
+	t.Parallel()
+
+	xPub := "xpub-addresses-options"
+	server, client := newFakeServer(t, nil, xpubAddresses(xPub, 25))
+
+	addresses, err := client.GetXpubAddressesPage(xPub, &bitindex.GetXpubAddressesOptions{Limit: 5, Offset: 2, Order: bitindex.SortDescending})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(addresses) != 5 || addresses[0].Address != "1address22" {
+		t.Fatalf("unexpected addresses: %+v", addresses)
+	}
+	if addresses, err = client.GetXpubAddressesPage(xPub, &bitindex.GetXpubAddressesOptions{Address: " 1address3 "}); err != nil || len(addresses) != 1 {
+		t.Fatalf("expected 1 address got: %d %v", len(addresses), err)
+	}
+	if addresses, err = client.GetXpubAddressesPage(xPub, nil); err != nil || len(addresses) != 25 {
+		t.Fatalf("expected 25 addresses got: %d %v", len(addresses), err)
+	}
+	queries := addressQueries(server, xPub)
+	if len(queries) != 3 || queries[0] != "limit=5&offset=2&order=desc" || queries[1] != "address=1address3" || queries[2] != "" {
+		t.Fatalf("unexpected queries: %v", queries)
+	}
+
+	// The deprecated parameters are the same request
+	if addresses, err = client.GetXpubAddresses(xPub, 2, 5, "DESC", ""); err != nil {
+		t.Fatal(err)
+	} else if len(addresses) != 5 || addresses[0].Address != "1address22" {
+		t.Fatalf("unexpected addresses: %+v", addresses)
+	} else if queries = addressQueries(server, xPub); len(queries) != 4 || queries[3] != queries[0] {
+		t.Fatalf("unexpected queries: %v", queries)
+	}
+
+	// Invalid options are not sent
+	if _, err = client.GetXpubAddressesPage(xPub, &bitindex.GetXpubAddressesOptions{Limit: bitindex.MaxXpubAddressesLimit + 1}); !errors.Is(err, bitindex.ErrInvalidLimit) {
+		t.Fatalf("expected ErrInvalidLimit got: %v", err)
+	} else if queries = addressQueries(server, xPub); len(queries) != 4 {
+		t.Fatalf("expected 4 requests got: %d", len(queries))
+	}
+}
+
+// TestXpubAddressIterator tests walking every page of the addresses
+func TestXpubAddressIterator(t *testing.T) {
+	t.Parallel()
+
+	xPub := "xpub-addresses-iterator"
+
+	t.Run("all pages", func(t *testing.T) {
+		server, client := newFakeServer(t, nil, xpubAddresses(xPub, 25))
+
+		iterator := bitindex.NewXpubAddressIterator(context.Background(), client, xPub, &bitindex.GetXpubAddressesOptions{Limit: 10, Offset: 2, Order: bitindex.SortDescending})
+		var addresses []string
+		for iterator.Next() {
+			addresses = append(addresses, iterator.Address().Address)
+		}
+		if err := iterator.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if len(addresses) != 23 || addresses[0] != "1address22" || addresses[22] != "1address0" {
+			t.Fatalf("unexpected addresses: %v", addresses)
+		}
+		if queries := addressQueries(server, xPub); len(queries) != 3 || queries[2] != "limit=10&offset=22&order=desc" {
+			t.Fatalf("unexpected queries: %v", queries)
+		}
+		if iterator.Next() || iterator.Address() != nil {
+			t.Fatal("expected the iterator to be done")
+		}
+	})
+
+	t.Run("full last page", func(t *testing.T) {
+		server, client := newFakeServer(t, nil, xpubAddresses(xPub, 20))
+		iterator := bitindex.NewXpubAddressIterator(context.Background(), client, xPub, &bitindex.GetXpubAddressesOptions{Limit: 10})
+		count := 0
+		for iterator.Next() {
+			count++
+		}
+		if queries := addressQueries(server, xPub); iterator.Err() != nil || count != 20 || len(queries) != 3 {
+			t.Fatalf("expected 20 addresses in 3 requests got: %d %d %v", count, len(queries), iterator.Err())
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		server, client := newFakeServer(t, nil, xpubAddresses(xPub, 25))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		iterator := bitindex.NewXpubAddressIterator(ctx, client, xPub, &bitindex.GetXpubAddressesOptions{Limit: 10})
+		count := 0
+		for iterator.Next() {
+			if count++; count == 5 {
+				cancel()
+			}
+		}
+		if queries := addressQueries(server, xPub); !errors.Is(iterator.Err(), context.Canceled) || count != 5 || len(queries) != 1 {
+			t.Fatalf("expected canceled after 5 addresses got: %d %v", count, iterator.Err())
+		}
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		server, client := newFakeServer(t, nil, xpubAddresses(xPub, 25))
+		iterator := bitindex.NewXpubAddressIterator(context.Background(), client, xPub, &bitindex.GetXpubAddressesOptions{Order: "up"})
+		if iterator.Next() || !errors.Is(iterator.Err(), bitindex.ErrInvalidSort) || len(server.Requests()) != 0 {
+			t.Fatalf("expected ErrInvalidSort got: %v", iterator.Err())
+		}
+	})
+}
@@ -0,0 +1,234 @@
+package bitindex
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec"
+)
+
+// Xpub chains (the first level below the xpub)
+const (
+	ExternalChain uint32 = 0 // receiving addresses
+	InternalChain uint32 = 1 // change addresses
+)
+
+// HardenedKeyStart is the first hardened child index (can not be derived from an xpub)
+const HardenedKeyStart uint32 = 0x80000000
+
+// Extended public key versions (xpub & tpub)
+var (
+	mainPublicKeyVersion = []byte{0x04, 0x88, 0xb2, 0x1e}
+	testPublicKeyVersion = []byte{0x04, 0x35, 0x87, 0xcf}
+)
+
+// extendedKeySize is the size of a serialized extended key
+const extendedKeySize = 78
+
+var (
+	// ErrInvalidXpub is returned when an extended public key can not be parsed
+	ErrInvalidXpub = errors.New("invalid xpub")
+
+	// ErrHardenedDerivation is returned when deriving a hardened child from an xpub
+	ErrHardenedDerivation = errors.New("can not derive a hardened child from an xpub")
+
+	// ErrXpubAddressMismatch is returned when an address is not the one derived from the xpub
+	ErrXpubAddressMismatch = errors.New("xpub address mismatch")
+)
+
+// ExtendedPublicKey is a BIP32 extended public key (xpub for main, tpub for test and stn)
+type ExtendedPublicKey struct {
+	ChainCode         []byte      // is the chain code (32 bytes)
+	ChildNumber       uint32      // is the index of this key in the parent
+	Depth             uint8       // is the depth from the master key
+	Network           NetworkType // is the network of the key version
+	ParentFingerprint []byte      // is the first 4 bytes of the hash160 of the parent key
+	PublicKey         []byte      // is the compressed public key (33 bytes)
+}
+
+// ParseXpub will parse the extended public key (IE: xpub661MyMwAqRbc...)
+func ParseXpub(xPub string) (*ExtendedPublicKey, error) {
+	data, err := base58CheckDecode(strings.TrimSpace(xPub))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidXpub, err.Error())
+	} else if len(data) != extendedKeySize {
+		return nil, fmt.Errorf("%w: %d bytes instead of %d", ErrInvalidXpub, len(data), extendedKeySize)
+	}
+
+	key := &ExtendedPublicKey{
+		ChainCode:         data[13:45],
+		ChildNumber:       binary.BigEndian.Uint32(data[9:13]),
+		Depth:             data[4],
+		ParentFingerprint: data[5:9],
+		PublicKey:         data[45:78],
+	}
+	switch {
+	case bytes.Equal(data[:4], mainPublicKeyVersion):
+		key.Network = NetworkMain
+	case bytes.Equal(data[:4], testPublicKeyVersion):
+		key.Network = NetworkTest
+	default:
+		return nil, fmt.Errorf("%w: unknown version %x (private keys are not accepted)", ErrInvalidXpub, data[:4])
+	}
+	if _, err = btcec.ParsePubKey(key.PublicKey, btcec.S256()); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidXpub, err.Error())
+	}
+	return key, nil
+}
+
+// String returns the serialized extended public key (xpub or tpub)
+func (k *ExtendedPublicKey) String() string {
+	data := make([]byte, 0, extendedKeySize)
+	if k.Network == NetworkMain {
+		data = append(data, mainPublicKeyVersion...)
+	} else {
+		data = append(data, testPublicKeyVersion...)
+	}
+	var childNumber [4]byte
+	binary.BigEndian.PutUint32(childNumber[:], k.ChildNumber)
+	data = append(append(append(data, k.Depth), k.ParentFingerprint...), childNumber[:]...)
+	data = append(append(data, k.ChainCode...), k.PublicKey...)
+	return base58Encode(append(data, doubleSha256(data)[:4]...))
+}
+
+// Child will derive the (non-hardened) child key at the index
+func (k *ExtendedPublicKey) Child(index uint32) (*ExtendedPublicKey, error) {
+	if index >= HardenedKeyStart {
+		return nil, fmt.Errorf("%w: %d", ErrHardenedDerivation, index)
+	}
+	curve := btcec.S256()
+	parent, err := btcec.ParsePubKey(k.PublicKey, curve)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidXpub, err.Error())
+	}
+
+	// I = HMAC-SHA512(chain code, public key || index)
+	var indexBytes [4]byte
+	binary.BigEndian.PutUint32(indexBytes[:], index)
+	mac := hmac.New(sha512.New, k.ChainCode)
+	_, _ = mac.Write(k.PublicKey)
+	_, _ = mac.Write(indexBytes[:])
+	sum := mac.Sum(nil)
+
+	// The child key is IL * G + parent (invalid for about 1 in 2^127 indexes, use the next index)
+	if new(big.Int).SetBytes(sum[:32]).Cmp(curve.N) >= 0 {
+		return nil, fmt.Errorf("%w: child %d is invalid", ErrInvalidXpub, index)
+	}
+	child := &btcec.PublicKey{Curve: curve}
+	child.X, child.Y = curve.ScalarBaseMult(sum[:32])
+	if child.X, child.Y = curve.Add(child.X, child.Y, parent.X, parent.Y); child.X.Sign() == 0 && child.Y.Sign() == 0 {
+		return nil, fmt.Errorf("%w: child %d is invalid", ErrInvalidXpub, index)
+	}
+
+	return &ExtendedPublicKey{
+		ChainCode:         sum[32:],
+		ChildNumber:       index,
+		Depth:             k.Depth + 1,
+		Network:           k.Network,
+		ParentFingerprint: hash160(k.PublicKey)[:4],
+		PublicKey:         child.SerializeCompressed(),
+	}, nil
+}
+
+// DerivePath will derive the key at the relative path (IE: "0/5" or "m/1/2")
+func (k *ExtendedPublicKey) DerivePath(path string) (*ExtendedPublicKey, error) {
+	key := k
+	path = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(path), "m"), "/")
+	if len(path) == 0 {
+		return key, nil
+	}
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasSuffix(segment, "'") || strings.HasSuffix(segment, "H") || strings.HasSuffix(segment, "h") {
+			return nil, fmt.Errorf("%w: %s", ErrHardenedDerivation, path)
+		}
+		index, err := strconv.ParseUint(segment, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid path %q", ErrInvalidXpub, path)
+		}
+		if key, err = key.Child(uint32(index)); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// Address returns the P2PKH address of the key (for the network of the key)
+func (k *ExtendedPublicKey) Address() string {
+	return hash160Address(hash160(k.PublicKey), k.Network, false)
+}
+
+// AddressAt returns the address of the chain (ExternalChain or InternalChain) and number
+func (k *ExtendedPublicKey) AddressAt(chain, num uint32) (string, error) {
+	chainKey, err := k.Child(chain)
+	if err != nil {
+		return "", err
+	}
+	key, err := chainKey.Child(num)
+	if err != nil {
+		return "", err
+	}
+	return key.Address(), nil
+}
+
+// Addresses returns count addresses of the chain starting at the number
+func (k *ExtendedPublicKey) Addresses(chain, start, count uint32) (addresses []string, err error) {
+	var chainKey, key *ExtendedPublicKey
+	if chainKey, err = k.Child(chain); err != nil {
+		return
+	}
+	for num := start; num < start+count; num++ {
+		if key, err = chainKey.Child(num); err != nil {
+			return
+		}
+		addresses = append(addresses, key.Address())
+	}
+	return
+}
+
+// VerifyXpubAddresses will derive every address from the xpub and compare it to the returned address
+//
+// The Path of each address is used (IE: "0/5"), or Chain/Num if there is no path. Returns
+// ErrXpubAddressMismatch with the first address that does not match
+func VerifyXpubAddresses(xPub string, addresses XpubAddresses) error {
+	key, err := ParseXpub(xPub)
+	if err != nil {
+		return err
+	}
+
+	// Derive each chain once
+	chains := make(map[string]*ExtendedPublicKey)
+	for _, address := range addresses {
+		path := address.Path
+		if len(path) == 0 {
+			path = strconv.Itoa(address.Chain) + "/" + strconv.Itoa(address.Num)
+		}
+		path = strings.TrimPrefix(strings.TrimPrefix(path, "m"), "/")
+
+		chainPath, num := "", path
+		if i := strings.LastIndex(path, "/"); i >= 0 {
+			chainPath, num = path[:i], path[i+1:]
+		}
+		chainKey, ok := chains[chainPath]
+		if !ok {
+			if chainKey, err = key.DerivePath(chainPath); err != nil {
+				return err
+			}
+			chains[chainPath] = chainKey
+		}
+
+		var derived *ExtendedPublicKey
+		if derived, err = chainKey.DerivePath(num); err != nil {
+			return err
+		} else if derived.Address() != address.Address {
+			return fmt.Errorf("%w: %s derives %s not %s", ErrXpubAddressMismatch, path, derived.Address(), address.Address)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package bitindex
+
+import (
+	"errors"
+	"testing"
+)
+
+// BIP32 test vector 1 (m/0H/1/2H/2 and its child 1000000000)
+const (
+	testXpub       = "xpub6FHa3pjLCk84BayeJxFW2SP4XRrFd1JYnxeLeU8EqN3vDfZmbqBqaGJAyiLjTAwm6ZLRQUMv1ZACTj37sR62cfN7fe5JnJ7dh8zL4fiyLHV"
+	testXpubChild  = "xpub6H1LXWLaKsWFhvm6RVpEL9P4KfRZSW7abD2ttkWP3SSQvnyA8FSVqNTEcYFgJS2UaFcxupHiYkro49S8yGasTvXEYBVPamhGW6cFJodrTHy"
+	testXpubParent = "xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw"
+	testXpubChild1 = "xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ"
+)
+
+// TestParseXpub tests parsing and serializing extended public keys
+func TestParseXpub(t *testing.T) {
+	t.Parallel()
+
+	key, err := ParseXpub(testXpub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.Network != NetworkMain || key.Depth != 4 || key.ChildNumber != 2 || len(key.PublicKey) != 33 {
+		t.Fatalf("unexpected key: %+v", key)
+	}
+	if key.String() != testXpub {
+		t.Fatalf("expected %s got: %s", testXpub, key.String())
+	}
+
+	// Testnet version
+	key.Network = NetworkTest
+	tpub := key.String()
+	if tpub[:4] != "tpub" {
+		t.Fatalf("expected a tpub got: %s", tpub)
+	}
+	if key, err = ParseXpub(tpub); err != nil {
+		t.Fatal(err)
+	} else if key.Network != NetworkTest || key.Address()[:1] == "1" {
+		t.Fatalf("expected a testnet key and address got: %s %s", key.Network, key.Address())
+	}
+
+	var tests = []string{
+		"",
+		"xpub",
+		testXpub[:len(testXpub)-1] + "W",
+		"xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi",
+	}
+	for _, test := range tests {
+		if _, err = ParseXpub(test); !errors.Is(err, ErrInvalidXpub) {
+			t.Fatalf("%q: expected ErrInvalidXpub got: %v", test, err)
+		}
+	}
+}
+
+// TestExtendedPublicKey_Child tests the public derivation against the BIP32 test vectors
+func TestExtendedPublicKey_Child(t *testing.T) {
+	t.Parallel()
+
+	var tests = []struct {
+		parent   string
+		index    uint32
+		expected string
+	}{
+		{testXpub, 1000000000, testXpubChild},
+		{testXpubParent, 1, testXpubChild1},
+	}
+	for _, test := range tests {
+		key, err := ParseXpub(test.parent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		child, err := key.Child(test.index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if child.String() != test.expected {
+			t.Fatalf("%d: expected %s got: %s", test.index, test.expected, child.String())
+		}
+	}
+
+	key, err := ParseXpub(testXpub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = key.Child(HardenedKeyStart); !errors.Is(err, ErrHardenedDerivation) {
+		t.Fatalf("expected ErrHardenedDerivation got: %v", err)
+	}
+	if _, err = key.DerivePath("0/1'"); !errors.Is(err, ErrHardenedDerivation) {
+		t.Fatalf("expected ErrHardenedDerivation got: %v", err)
+	}
+	if child, err := key.DerivePath("m/1000000000"); err != nil || child.String() != testXpubChild {
+		t.Fatalf("expected the child from the path got: %v", err)
+	}
+	if _, err = key.DerivePath("0/x"); !errors.Is(err, ErrInvalidXpub) {
+		t.Fatalf("expected ErrInvalidXpub got: %v", err)
+	}
+}
+
+// BenchmarkExtendedPublicKey_Child benchmarks deriving a child key
+func BenchmarkExtendedPublicKey_Child(b *testing.B) {
+	key, err := ParseXpub(testXpub)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = key.Child(uint32(i) % HardenedKeyStart)
+	}
+}
+
+// TestVerifyXpubAddresses tests checking the api addresses against the derived addresses
+func TestVerifyXpubAddresses(t *testing.T) {
+	t.Parallel()
+
+	key, err := ParseXpub(testXpub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	external, err := key.Addresses(ExternalChain, 0, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	change, err := key.AddressAt(InternalChain, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(external) != 3 || external[0] == external[1] || external[0][:1] != "1" {
+		t.Fatalf("unexpected addresses: %v", external)
+	}
+
+	addresses := XpubAddresses{
+		{Address: external[0], Chain: 0, Num: 0, Path: "0/0"},
+		{Address: external[2], Chain: 0, Num: 2, Path: "m/0/2"},
+		{Address: change, Chain: 1, Num: 7},
+	}
+	if err = VerifyXpubAddresses(testXpub, addresses); err != nil {
+		t.Fatal(err)
+	}
+
+	// Wrong path for the address
+	addresses[1].Path = "0/1"
+	if err = VerifyXpubAddresses(testXpub, addresses); !errors.Is(err, ErrXpubAddressMismatch) {
+		t.Fatalf("expected ErrXpubAddressMismatch got: %v", err)
+	}
+	if err = VerifyXpubAddresses("xpub-invalid", addresses); !errors.Is(err, ErrInvalidXpub) {
+		t.Fatalf("expected ErrInvalidXpub got: %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package bitindex
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// DefaultGapLimit is the number of unused addresses in a row that ends a chain (BIP44)
+const DefaultGapLimit = 20
+
+// XpubScanOptions are the options for ScanXpub()
+type XpubScanOptions struct {
+	Batch    *BatchOptions // are the options for the batched requests (nil uses the defaults)
+	GapLimit int           // is the number of unused addresses in a row that ends a chain (0 uses DefaultGapLimit)
+}
+
+// XpubScanAddress is a used address found by ScanXpub()
+type XpubScanAddress struct {
+	Address      string              // is the address
+	Balance      Satoshis            // is the total of the UTXOs (confirmed & unconfirmed)
+	Chain        uint32              // is the chain (ExternalChain or InternalChain)
+	Num          uint32              // is the index in the chain
+	Path         string              // is the path from the xpub (IE: 0/5)
+	Transactions int                 // is the number of transactions
+	UTXOs        UnspentTransactions // are the unspent outputs
+}
+
+// XpubScanResult is the result of ScanXpub()
+type XpubScanResult struct {
+	Addresses        []XpubScanAddress // are the used addresses (by chain, then index)
+	Balance          Satoshis          // is the total balance of the xpub
+	NextChangeIndex  uint32            // is the first unused change index (after the last used)
+	NextReceiveIndex uint32            // is the first unused receive index (after the last used)
+}
+
+// ScanXpub will discover the used addresses of the xpub by deriving them locally
+//
+// Both chains are scanned in windows of gap limit addresses (with the batched requests of
+// the service) until the gap limit of unused addresses follows the last used address
+func ScanXpub(ctx context.Context, service AddressService, xPub string, options *XpubScanOptions) (result *XpubScanResult, err error) {
+	var key *ExtendedPublicKey
+	if key, err = ParseXpub(xPub); err != nil {
+		return
+	}
+	if options == nil {
+		options = &XpubScanOptions{}
+	}
+	gapLimit := uint32(DefaultGapLimit)
+	if options.GapLimit > 0 {
+		gapLimit = uint32(options.GapLimit)
+	}
+
+	result = new(XpubScanResult)
+	for _, chain := range []uint32{ExternalChain, InternalChain} {
+		var used []XpubScanAddress
+		var next uint32
+		if used, next, err = scanXpubChain(ctx, service, key, chain, gapLimit, options.Batch); err != nil {
+			return nil, err
+		}
+		result.Addresses = append(result.Addresses, used...)
+		if chain == ExternalChain {
+			result.NextReceiveIndex = next
+		} else {
+			result.NextChangeIndex = next
+		}
+	}
+
+	// Balances of the used addresses
+	if len(result.Addresses) == 0 {
+		return
+	}
+	index := make(map[string]int, len(result.Addresses))
+	addresses := make([]string, 0, len(result.Addresses))
+	for i, address := range result.Addresses {
+		index[address.Address] = i
+		addresses = append(addresses, address.Address)
+	}
+	var utxos UnspentTransactions
+	if utxos, err = GetUnspentTransactionsBatched(ctx, service, &GetUnspentTransactionsRequest{Addresses: addresses}, options.Batch); err != nil {
+		return nil, err
+	}
+	for _, utxo := range utxos {
+		if i, ok := index[utxo.Address]; ok {
+			result.Addresses[i].UTXOs = append(result.Addresses[i].UTXOs, utxo)
+			result.Addresses[i].Balance = result.Addresses[i].Balance.Add(utxo.satoshis())
+			result.Balance = result.Balance.Add(utxo.satoshis())
+		}
+	}
+	return
+}
+
+// scanXpubChain returns the used addresses of the chain and the next unused index
+func scanXpubChain(ctx context.Context, service AddressService, key *ExtendedPublicKey, chain, gapLimit uint32, batch *BatchOptions) (used []XpubScanAddress, next uint32, err error) {
+	var chainKey *ExtendedPublicKey
+	if chainKey, err = key.Child(chain); err != nil {
+		return
+	}
+
+	// Scan windows until the gap limit follows the last used address
+	var scanned uint32
+	for scanned < next+gapLimit {
+		window := make(map[string]*XpubScanAddress)
+		addresses := make([]string, 0, next+gapLimit-scanned)
+		for num := scanned; num < next+gapLimit; num++ {
+			var child *ExtendedPublicKey
+			if child, err = chainKey.Child(num); err != nil {
+				return
+			}
+			address := child.Address()
+			window[address] = &XpubScanAddress{Address: address, Chain: chain, Num: num, Path: fmt.Sprintf("%d/%d", chain, num)}
+			addresses = append(addresses, address)
+		}
+		scanned = next + gapLimit
+
+		var transactions []Transaction
+		if transactions, err = GetTransactionsBatched(ctx, service, &GetTransactionsRequest{Addresses: addresses}, batch); err != nil {
+			return
+		}
+		for i := range transactions {
+			for _, address := range transactions[i].Addresses() {
+				if found, ok := window[address]; ok {
+					found.Transactions++
+				}
+			}
+		}
+
+		// Used addresses move the end of the scan
+		var found []XpubScanAddress
+		for _, address := range window {
+			if address.Transactions > 0 {
+				found = append(found, *address)
+				if address.Num >= next {
+					next = address.Num + 1
+				}
+			}
+		}
+		sort.Slice(found, func(i, j int) bool { return found[i].Num < found[j].Num })
+		used = append(used, found...)
+	}
+	return
+}
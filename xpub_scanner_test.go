@@ -0,0 +1,124 @@
+package bitindex_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/mrz1836/go-bitindex"
+	"github.com/mrz1836/go-bitindex/bitindextest"
+)
+
+// scanXpub is BIP32 test vector 1 (m/0H/1/2H/2)
+const scanXpub = "xpub6FHa3pjLCk84BayeJxFW2SP4XRrFd1JYnxeLeU8EqN3vDfZmbqBqaGJAyiLjTAwm6ZLRQUMv1ZACTj37sR62cfN7fe5JnJ7dh8zL4fiyLHV"
+
+// walletTransactions adds one transaction (with one UTXO) for the address at each path of the xpub
+func walletTransactions(xPub string, paths map[string]int64) serverSeed {
+	return func(t *testing.T, server *bitindextest.Server) {
+		key, err := bitindex.ParseXpub(xPub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for path, satoshis := range paths {
+			child, err := key.DerivePath(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			server.AddTransaction(bitindextest.NewTransaction("tx-"+path, 100, nil, []bitindextest.Output{{Address: child.Address(), Satoshis: satoshis}}))
+		}
+	}
+}
+
+// queriedAddresses returns the number of times each address was in a transactions request
+func queriedAddresses(server *bitindextest.Server) map[string]int {
+	queried := make(map[string]int)
+	for _, batch := range batchRequests(server, "addrs/txs") {
+		for _, address := range batch {
+			queried[address]++
+		}
+	}
+	return queried
+}
+
+// TestScanXpub tests the gap limit scan of both chains
+func TestScanXpub(t *testing.T) {
+	t.Parallel()
+
+	paths := map[string]int64{"0/0": 1000, "0/1": 2000, "0/5": 4000, "1/0": 500}
+
+	t.Run("gap limit 3", func(t *testing.T) {
+		server, client := newFakeServer(t, nil, walletTransactions(scanXpub, paths))
+
+		result, err := bitindex.ScanXpub(context.Background(), client, scanXpub, &bitindex.XpubScanOptions{Batch: &bitindex.BatchOptions{BatchSize: 2}, GapLimit: 3})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// 0/5 is after a gap of 3 (0/2, 0/3, 0/4)
+		if len(result.Addresses) != 3 || result.Addresses[0].Path != "0/0" || result.Addresses[1].Path != "0/1" || result.Addresses[2].Path != "1/0" {
+			t.Fatalf("unexpected addresses: %+v", result.Addresses)
+		}
+		if result.NextReceiveIndex != 2 || result.NextChangeIndex != 1 {
+			t.Fatalf("unexpected next indexes: %d %d", result.NextReceiveIndex, result.NextChangeIndex)
+		}
+		if result.Balance != 3500 || result.Addresses[1].Balance != 2000 || len(result.Addresses[1].UTXOs) != 1 || result.Addresses[1].Transactions != 1 {
+			t.Fatalf("unexpected balances: %+v", result)
+		}
+
+		// Every address is queried once (receive 0-4, change 0-3)
+		queried := queriedAddresses(server)
+		if len(queried) != 9 {
+			t.Fatalf("expected 9 addresses queried got: %d", len(queried))
+		}
+		for address, count := range queried {
+			if count != 1 {
+				t.Fatalf("expected %s once got: %d", address, count)
+			}
+		}
+	})
+
+	t.Run("gap limit 4", func(t *testing.T) {
+		_, client := newFakeServer(t, nil, walletTransactions(scanXpub, paths))
+
+		result, err := bitindex.ScanXpub(context.Background(), client, scanXpub, &bitindex.XpubScanOptions{GapLimit: 4})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Addresses) != 4 || result.Addresses[2].Path != "0/5" || result.Addresses[2].Num != 5 {
+			t.Fatalf("unexpected addresses: %+v", result.Addresses)
+		}
+		if result.NextReceiveIndex != 6 || result.Balance != 7500 {
+			t.Fatalf("unexpected result: %d %d", result.NextReceiveIndex, result.Balance)
+		}
+	})
+
+	t.Run("unused xpub", func(t *testing.T) {
+		server, client := newFakeServer(t, nil, walletTransactions(scanXpub, nil))
+
+		result, err := bitindex.ScanXpub(context.Background(), client, scanXpub, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if queried := queriedAddresses(server); len(result.Addresses) != 0 || result.NextReceiveIndex != 0 || len(queried) != 2*bitindex.DefaultGapLimit {
+			t.Fatalf("unexpected result: %+v (%d queried)", result, len(queried))
+		}
+	})
+}
+
+// TestScanXpub_Errors tests the invalid xpub and failed requests
+func TestScanXpub_Errors(t *testing.T) {
+	t.Parallel()
+
+	server, client := newFakeServer(t, noRetryOptions(), walletTransactions(scanXpub, map[string]int64{"0/0": 1000}))
+	server.FailMatching(http.StatusTooManyRequests, func(req bitindextest.Request) bool {
+		return req.Path == "addrs/utxo"
+	})
+
+	if _, err := bitindex.ScanXpub(context.Background(), client, "xpub-invalid", nil); !errors.Is(err, bitindex.ErrInvalidXpub) {
+		t.Fatalf("expected ErrInvalidXpub got: %v", err)
+	}
+	if _, err := bitindex.ScanXpub(context.Background(), client, scanXpub, nil); !errors.Is(err, bitindex.ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited got: %v", err)
+	}
+}
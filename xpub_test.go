@@ -1,16 +1,14 @@
 package bitindex
 
-import "testing"
+import (
+	"errors"
+	"testing"
+)
 
 // TestClient_GetXpubNextAddress tests the GetXpubNextAddress()
 func TestClient_GetXpubNextAddress(t *testing.T) {
-	// Skip this test in short mode (not needed)
-	if testing.Short() {
-		t.Skip("skipping testing in short mode")
-	}
-
-	// Create a new client object to handle your queries (supply an API Key)
-	client, err := NewClient(testAPIKey, NetworkMain, nil)
+	// Create a new client object to handle your queries (replays the cassette if recorded)
+	client, err := newCassetteClient(t, NetworkMain)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -40,13 +38,8 @@ func TestClient_GetXpubNextAddress(t *testing.T) {
 
 // TestClient_GetXpubAddresses tests the GetXpubAddresses()
 func TestClient_GetXpubAddresses(t *testing.T) {
-	// Skip this test in short mode (not needed)
-	if testing.Short() {
-		t.Skip("skipping testing in short mode")
-	}
-
-	// Create a new client object to handle your queries (supply an API Key)
-	client, err := NewClient(testAPIKey, NetworkMain, nil)
+	// Create a new client object to handle your queries (replays the cassette if recorded)
+	client, err := newCassetteClient(t, NetworkMain)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -80,13 +73,8 @@ func TestClient_GetXpubAddresses(t *testing.T) {
 
 // TestClient_GetXpubBalance tests the GetXpubBalance()
 func TestClient_GetXpubBalance(t *testing.T) {
-	// Skip this test in short mode (not needed)
-	if testing.Short() {
-		t.Skip("skipping testing in short mode")
-	}
-
-	// Create a new client object to handle your queries (supply an API Key)
-	client, err := NewClient(testAPIKey, NetworkMain, nil)
+	// Create a new client object to handle your queries (replays the cassette if recorded)
+	client, err := newCassetteClient(t, NetworkMain)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -108,13 +96,8 @@ func TestClient_GetXpubBalance(t *testing.T) {
 
 // TestClient_GetXpubUnspentTransactions tests the GetXpubUnspentTransactions()
 func TestClient_GetXpubUnspentTransactions(t *testing.T) {
-	// Skip this test in short mode (not needed)
-	if testing.Short() {
-		t.Skip("skipping testing in short mode")
-	}
-
-	// Create a new client object to handle your queries (supply an API Key)
-	client, err := NewClient(testAPIKey, NetworkMain, nil)
+	// Create a new client object to handle your queries (replays the cassette if recorded)
+	client, err := newCassetteClient(t, NetworkMain)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -139,13 +122,8 @@ func TestClient_GetXpubUnspentTransactions(t *testing.T) {
 
 // TestClient_GetXpubTransactions tests the GetXpubTransactions()
 func TestClient_GetXpubTransactions(t *testing.T) {
-	// Skip this test in short mode (not needed)
-	if testing.Short() {
-		t.Skip("skipping testing in short mode")
-	}
-
-	// Create a new client object to handle your queries (supply an API Key)
-	client, err := NewClient(testAPIKey, NetworkMain, nil)
+	// Create a new client object to handle your queries (replays the cassette if recorded)
+	client, err := newCassetteClient(t, NetworkMain)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -161,3 +139,40 @@ func TestClient_GetXpubTransactions(t *testing.T) {
 		t.Fatal("we should have the some transactions", transactions, xPub)
 	}
 }
+
+// TestGetXpubAddressesOptions_Validate tests the bounds of the options
+func TestGetXpubAddressesOptions_Validate(t *testing.T) {
+	t.Parallel()
+
+	var tests = []struct {
+		options  *GetXpubAddressesOptions
+		expected error
+	}{
+		{nil, nil},
+		{&GetXpubAddressesOptions{}, nil},
+		{&GetXpubAddressesOptions{Limit: MaxXpubAddressesLimit, Offset: 10, Order: "DESC"}, nil},
+		{&GetXpubAddressesOptions{Limit: -1}, ErrInvalidLimit},
+		{&GetXpubAddressesOptions{Limit: MaxXpubAddressesLimit + 1}, ErrInvalidLimit},
+		{&GetXpubAddressesOptions{Offset: -1}, ErrInvalidLimit},
+		{&GetXpubAddressesOptions{Order: "newest"}, ErrInvalidSort},
+	}
+	for _, test := range tests {
+		if err := test.options.Validate(); !errors.Is(err, test.expected) {
+			t.Fatalf("%+v: expected %v got: %v", test.options, test.expected, err)
+		}
+	}
+}
+
+// TestMemoryTransactionCache tests evicting the oldest transaction
+func TestMemoryTransactionCache(t *testing.T) {
+	t.Parallel()
+
+	cache := NewMemoryTransactionCache(1)
+	cache.Set("a", &Transaction{TxID: "a"})
+	cache.Set("b", &Transaction{TxID: "b"})
+	if _, ok := cache.Get("a"); ok {
+		t.Fatal("expected a to be evicted")
+	} else if tx, ok := cache.Get("b"); !ok || tx.TxID != "b" {
+		t.Fatal("expected b to be cached")
+	}
+}
@@ -0,0 +1,249 @@
+package bitindex
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// DefaultTransactionCacheSize is the number of transactions kept by NewMemoryTransactionCache(0)
+const DefaultTransactionCacheSize = 10000
+
+// TransactionCache is a cache of fetched transactions (only confirmed transactions are stored)
+//
+// Implement it with any shared storage (IE: Redis), or use NewMemoryTransactionCache(). Copies are
+// stored and the cached transactions are copied before they are returned, they are never modified
+type TransactionCache interface {
+	// Get returns the transaction if it is cached
+	Get(txID string) (transaction *Transaction, ok bool)
+
+	// Set stores the transaction
+	Set(txID string, transaction *Transaction)
+}
+
+// MemoryTransactionCache is an in-memory TransactionCache (the oldest transactions are evicted first)
+type MemoryTransactionCache struct {
+	mu           sync.Mutex              // is the lock for the transactions
+	order        []string                // are the cached txids (oldest first)
+	size         int                     // is the maximum number of transactions
+	transactions map[string]*Transaction // are the cached transactions
+}
+
+// NewMemoryTransactionCache creates an in-memory cache of size transactions (0 uses DefaultTransactionCacheSize)
+func NewMemoryTransactionCache(size int) *MemoryTransactionCache {
+	if size <= 0 {
+		size = DefaultTransactionCacheSize
+	}
+	return &MemoryTransactionCache{size: size, transactions: make(map[string]*Transaction)}
+}
+
+// Get returns the transaction if it is cached
+func (m *MemoryTransactionCache) Get(txID string) (*Transaction, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	transaction, ok := m.transactions[txID]
+	return transaction, ok
+}
+
+// Set stores the transaction (evicting the oldest if the cache is full)
+func (m *MemoryTransactionCache) Set(txID string, transaction *Transaction) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.transactions[txID]; !ok {
+		m.order = append(m.order, txID)
+	}
+	m.transactions[txID] = transaction
+	for len(m.order) > m.size {
+		delete(m.transactions, m.order[0])
+		m.order = m.order[1:]
+	}
+}
+
+// XpubTransactionsOptions are the options for GetXpubTransactionDetails()
+type XpubTransactionsOptions struct {
+	Cache       TransactionCache // is the cache of fetched transactions (nil does not cache between calls)
+	Concurrency int              // is the number of GetTransaction requests at once (0 uses DefaultBatchConcurrency)
+}
+
+// XpubTransaction is a transaction of the xpub with the amounts for the wallet
+type XpubTransaction struct {
+	Addresses     XpubAddresses       // are the xpub history entries of the transaction
+	BlockHash     string              // is the block hash (empty if unconfirmed)
+	BlockHeight   int64               // is the block height (0 if unconfirmed)
+	BlockTime     int64               // is the block time (unix seconds)
+	Confirmations int64               // is the number of confirmations
+	Fees          Satoshis            // is the fee of the transaction
+	Inputs        []TransactionInput  // are the inputs spending from the xpub
+	NetAmount     Satoshis            // is the received minus the sent amount (negative if the wallet paid)
+	Outputs       []TransactionOutput // are the outputs paying the xpub
+	Received      Satoshis            // is the total of the outputs paying the xpub
+	Sent          Satoshis            // is the total of the inputs spending from the xpub
+	Transaction   *Transaction        // is the full transaction (a copy, safe to modify)
+	TxID          string              // is the transaction id
+}
+
+// GetXpubTransactionDetails will return the full details of every transaction of the xpub (newest first)
+//
+// The history (GetXpubTransactions) and the addresses (GetXpubAddressesPage) of the xpub are combined with
+// GetTransaction for each transaction to find the inputs and outputs that belong to the wallet. The
+// confirmations are from the current chain tip (ChainInfo), so they are current for cached transactions
+func GetXpubTransactionDetails(ctx context.Context, service XpubTransactionService, xPub string, options *XpubTransactionsOptions) (transactions []XpubTransaction, err error) {
+	if options == nil {
+		options = &XpubTransactionsOptions{}
+	}
+
+	// The history of the xpub (one entry for each address and transaction)
+	var history XpubAddresses
+	if history, err = service.GetXpubTransactionsWithContext(ctx, xPub); err != nil {
+		return
+	}
+
+	// Every address of the xpub is owned (inputs may spend from an address without an entry)
+	owned := make(map[string]struct{})
+	iterator := NewXpubAddressIterator(ctx, service, xPub, nil)
+	for iterator.Next() {
+		owned[iterator.Address().Address] = struct{}{}
+	}
+	if err = iterator.Err(); err != nil {
+		return
+	}
+
+	// Group the entries by transaction (in order of the history)
+	index := make(map[string]int)
+	for _, entry := range history {
+		if len(entry.Address) > 0 {
+			owned[entry.Address] = struct{}{}
+		}
+		if len(entry.TxID) == 0 {
+			continue
+		}
+		i, ok := index[entry.TxID]
+		if !ok {
+			i = len(transactions)
+			index[entry.TxID] = i
+			transactions = append(transactions, XpubTransaction{TxID: entry.TxID})
+		}
+		transactions[i].Addresses = append(transactions[i].Addresses, entry)
+	}
+
+	if len(transactions) == 0 {
+		return
+	}
+
+	// Get the transactions
+	if err = fetchXpubTransactions(ctx, service, transactions, options); err != nil {
+		return nil, err
+	}
+
+	// The chain tip (once) for the confirmations
+	var chainInfo *ChainInfoResponse
+	if chainInfo, err = service.ChainInfoWithContext(ctx); err != nil {
+		return nil, err
+	}
+
+	// The amounts for the wallet
+	for i := range transactions {
+		transactions[i].setDetails(owned, chainInfo.Info.Blocks)
+	}
+
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return newestFirst(transactions[i].BlockHeight, transactions[j].BlockHeight)
+	})
+	return
+}
+
+// fetchXpubTransactions will get each transaction (from the cache or GetTransaction) with the concurrency limit
+func fetchXpubTransactions(ctx context.Context, service TransactionService, transactions []XpubTransaction, options *XpubTransactionsOptions) error {
+	concurrency := DefaultBatchConcurrency
+	if options.Concurrency > 0 {
+		concurrency = options.Concurrency
+	}
+
+	// Stop the other requests on the first error
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var once sync.Once
+	var firstErr error
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range transactions {
+		if options.Cache != nil {
+			if tx, ok := options.Cache.Get(transactions[i].TxID); ok && tx != nil {
+				transactions[i].Transaction = cloneTransaction(tx)
+				continue
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+		case semaphore <- struct{}{}:
+			wg.Add(1)
+			go func(xpubTx *XpubTransaction) {
+				defer func() {
+					<-semaphore
+					wg.Done()
+				}()
+				tx, err := service.GetTransactionWithContext(ctx, xpubTx.TxID)
+				if err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					return
+				}
+				xpubTx.Transaction = tx
+				if options.Cache != nil && tx.BlockHeight > 0 {
+					options.Cache.Set(tx.TxID, cloneTransaction(tx))
+				}
+			}(&transactions[i])
+		}
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// cloneTransaction returns a copy of the transaction that shares no slices with it
+func cloneTransaction(tx *Transaction) *Transaction {
+	clone := *tx
+	clone.Errors = append([]string(nil), tx.Errors...)
+	clone.Vin = append([]TransactionInput(nil), tx.Vin...)
+	clone.Vout = append([]TransactionOutput(nil), tx.Vout...)
+	for i := range clone.Vout {
+		clone.Vout[i].ScriptPubKey.Addresses = append([]string(nil), tx.Vout[i].ScriptPubKey.Addresses...)
+	}
+	return &clone
+}
+
+// setDetails will set the block, amounts, inputs and outputs of the wallet from the transaction
+//
+// The confirmations are from the chain tip (the transaction is updated to match)
+func (x *XpubTransaction) setDetails(owned map[string]struct{}, tipHeight int64) {
+	tx := x.Transaction
+	if tx.BlockHeight > 0 && tipHeight >= tx.BlockHeight {
+		tx.Confirmations = tipHeight - tx.BlockHeight + 1
+	}
+	x.BlockHash, x.BlockHeight, x.BlockTime = tx.BlockHash, tx.BlockHeight, tx.BlockTime
+	x.Confirmations, x.Fees = tx.Confirmations, tx.Fees
+
+	for i := range tx.Vin {
+		if _, ok := owned[tx.Vin[i].FromAddress()]; ok {
+			x.Inputs = append(x.Inputs, tx.Vin[i])
+			x.Sent += tx.Vin[i].Value
+		}
+	}
+	for i := range tx.Vout {
+		for _, address := range tx.Vout[i].Addresses() {
+			if _, ok := owned[address]; ok {
+				x.Outputs = append(x.Outputs, tx.Vout[i])
+				x.Received += tx.Vout[i].Value
+				break
+			}
+		}
+	}
+	x.NetAmount = x.Received - x.Sent
+}
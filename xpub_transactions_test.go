@@ -0,0 +1,231 @@
+package bitindex_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrz1836/go-bitindex"
+	"github.com/mrz1836/go-bitindex/bitindextest"
+)
+
+// addBlocks adds the blocks (block-{height}) from one height to another, the last is the chain tip
+func addBlocks(server *bitindextest.Server, from, to int64) {
+	for height := from; height <= to; height++ {
+		server.AddBlock(bitindex.BlockResponse{Hash: fmt.Sprintf("block-%d", height), Height: height, Time: 1600000000 + height}, "")
+	}
+}
+
+// xpubHistory adds a wallet that received, spent (with change) and has an unconfirmed payment
+func xpubHistory(xPub string) serverSeed {
+	return func(_ *testing.T, server *bitindextest.Server) {
+		server.AddXpub(xPub,
+			bitindex.XPubAddress{Address: "1receive0", Path: "0/0"},
+			bitindex.XPubAddress{Address: "1receive1", Num: 1, Path: "0/1"},
+			bitindex.XPubAddress{Address: "1change0", Chain: 1, Path: "1/0"},
+		)
+
+		receive := bitindextest.NewTransaction("tx-receive", 100,
+			[]bitindextest.Input{{Address: "1stranger", Satoshis: 10000, TxID: "tx-previous"}},
+			[]bitindextest.Output{{Address: "1receive0", Satoshis: 8000}, {Address: "1stranger", Satoshis: 1500}},
+		)
+		receive.BlockHash, receive.BlockTime = "block-100", 1600000000
+		spend := bitindextest.NewTransaction("tx-spend", 102,
+			[]bitindextest.Input{{Address: "1receive0", Satoshis: 8000, TxID: "tx-receive"}},
+			[]bitindextest.Output{{Address: "1merchant", Satoshis: 5000}, {Address: "1change0", Satoshis: 2800}},
+		)
+		spend.BlockHash, spend.BlockTime = "block-102", 1600001000
+		server.AddTransaction(receive)
+		server.AddTransaction(spend)
+		server.AddTransaction(bitindextest.NewTransaction("tx-unconfirmed", 0,
+			[]bitindextest.Input{{Address: "1stranger", Satoshis: 1500, TxID: "tx-receive", Vout: 1}},
+			[]bitindextest.Output{{Address: "1receive1", Satoshis: 1400}},
+		))
+		addBlocks(server, 100, 102)
+	}
+}
+
+// transactionFetches returns the number of tx/{txid} requests for each txid
+func transactionFetches(server *bitindextest.Server) map[string]int {
+	fetches := make(map[string]int)
+	for _, request := range server.Requests() {
+		if strings.HasPrefix(request.Path, "tx/") {
+			fetches[strings.TrimPrefix(request.Path, "tx/")]++
+		}
+	}
+	return fetches
+}
+
+// TestGetXpubTransactionDetails tests the wallet amounts, inputs and outputs of each transaction
+func TestGetXpubTransactionDetails(t *testing.T) {
+	t.Parallel()
+
+	xPub := "xpub-details"
+	server, client := newFakeServer(t, nil, xpubHistory(xPub))
+	server.SetLatency(5 * time.Millisecond)
+
+	transactions, err := bitindex.GetXpubTransactionDetails(context.Background(), client, xPub, &bitindex.XpubTransactionsOptions{Concurrency: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transactions) != 3 || transactions[0].TxID != "tx-unconfirmed" || transactions[1].TxID != "tx-spend" || transactions[2].TxID != "tx-receive" {
+		t.Fatalf("expected newest first got: %+v", transactions)
+	}
+	if server.MaxInFlight() != 1 {
+		t.Fatalf("expected 1 request at once got: %d", server.MaxInFlight())
+	}
+
+	// Spent 8000, paid 5000 and 200 in fees, the change is owned
+	spend := transactions[1]
+	if spend.Sent != 8000 || spend.Received != 2800 || spend.NetAmount != -5200 || len(spend.Inputs) != 1 || len(spend.Outputs) != 1 {
+		t.Fatalf("unexpected spend: %+v", spend)
+	}
+	if spend.Confirmations != 1 || spend.BlockHash != "block-102" || spend.BlockTime != 1600001000 || spend.Fees != 200 || len(spend.Addresses) != 2 {
+		t.Fatalf("unexpected spend block: %+v", spend)
+	}
+
+	receive := transactions[2]
+	if receive.Sent != 0 || receive.NetAmount != 8000 || len(receive.Inputs) != 0 || receive.Outputs[0].Value != 8000 || receive.Confirmations != 3 {
+		t.Fatalf("unexpected receive: %+v", receive)
+	}
+	if receive.Transaction.Confirmations != receive.Confirmations {
+		t.Fatalf("expected the transaction to have the same confirmations got: %d", receive.Transaction.Confirmations)
+	}
+	if transactions[0].NetAmount != 1400 || transactions[0].Confirmations != 0 || transactions[0].Transaction == nil {
+		t.Fatalf("unexpected unconfirmed: %+v", transactions[0])
+	}
+}
+
+// TestGetXpubTransactionDetails_Cache tests reusing the confirmed transactions
+func TestGetXpubTransactionDetails_Cache(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unconfirmed is fetched again", func(t *testing.T) {
+		xPub := "xpub-details-cache"
+		server, client := newFakeServer(t, nil, xpubHistory(xPub))
+		options := &bitindex.XpubTransactionsOptions{Cache: bitindex.NewMemoryTransactionCache(0)}
+
+		if _, err := bitindex.GetXpubTransactionDetails(context.Background(), client, xPub, options); err != nil {
+			t.Fatal(err)
+		}
+
+		// The unconfirmed transaction is mined 8 blocks later
+		unconfirmed := bitindextest.NewTransaction("tx-unconfirmed", 110,
+			[]bitindextest.Input{{Address: "1stranger", Satoshis: 1500, TxID: "tx-receive", Vout: 1}},
+			[]bitindextest.Output{{Address: "1receive1", Satoshis: 1400}},
+		)
+		server.AddTransaction(unconfirmed)
+		addBlocks(server, 103, 110)
+
+		transactions, err := bitindex.GetXpubTransactionDetails(context.Background(), client, xPub, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fetches := transactionFetches(server); fetches["tx-receive"] != 1 || fetches["tx-spend"] != 1 || fetches["tx-unconfirmed"] != 2 {
+			t.Fatalf("expected the confirmed transactions once got: %v", fetches)
+		}
+		if transactions[0].TxID != "tx-unconfirmed" || transactions[0].Confirmations != 1 || transactions[1].Confirmations != 9 || transactions[2].Confirmations != 11 {
+			t.Fatalf("unexpected confirmations: %+v", transactions)
+		}
+	})
+
+	t.Run("every transaction is cached", func(t *testing.T) {
+		xPub := "xpub-details-cached"
+		server, client := newFakeServer(t, nil, xpubHistory(xPub))
+		server.AddTransaction(bitindextest.NewTransaction("tx-unconfirmed", 102,
+			[]bitindextest.Input{{Address: "1stranger", Satoshis: 1500, TxID: "tx-receive", Vout: 1}},
+			[]bitindextest.Output{{Address: "1receive1", Satoshis: 1400}},
+		))
+		options := &bitindex.XpubTransactionsOptions{Cache: bitindex.NewMemoryTransactionCache(0)}
+
+		if _, err := bitindex.GetXpubTransactionDetails(context.Background(), client, xPub, options); err != nil {
+			t.Fatal(err)
+		}
+
+		// The chain moves on, nothing is fetched again
+		addBlocks(server, 103, 105)
+		transactions, err := bitindex.GetXpubTransactionDetails(context.Background(), client, xPub, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for txID, count := range transactionFetches(server) {
+			if count != 1 {
+				t.Fatalf("expected %s to be fetched once got: %d", txID, count)
+			}
+		}
+		for _, transaction := range transactions {
+			if expected := 105 - transaction.BlockHeight + 1; transaction.Confirmations != expected || transaction.Transaction.Confirmations != expected {
+				t.Fatalf("expected %s to have %d confirmations got: %d", transaction.TxID, expected, transaction.Confirmations)
+			}
+		}
+	})
+
+	t.Run("results are copies", func(t *testing.T) {
+		xPub := "xpub-details-copies"
+		_, client := newFakeServer(t, nil, xpubHistory(xPub))
+		cache := bitindex.NewMemoryTransactionCache(0)
+		options := &bitindex.XpubTransactionsOptions{Cache: cache}
+
+		transactions, err := bitindex.GetXpubTransactionDetails(context.Background(), client, xPub, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		receive := transactions[2].Transaction
+		receive.BlockHeight = 1
+		receive.Vout[0].Value = 1
+		receive.Vout[0].ScriptPubKey.Addresses[0] = "1mutated"
+
+		if cached, ok := cache.Get("tx-receive"); !ok || cached == receive || cached.BlockHeight != 100 || cached.Vout[0].Value != 8000 || cached.Vout[0].ScriptPubKey.Addresses[0] != "1receive0" {
+			t.Fatalf("expected the cache to be unchanged got: %+v", cached)
+		}
+		if transactions, err = bitindex.GetXpubTransactionDetails(context.Background(), client, xPub, options); err != nil {
+			t.Fatal(err)
+		} else if transactions[2].NetAmount != 8000 || transactions[2].Confirmations != 3 {
+			t.Fatalf("unexpected receive: %+v", transactions[2])
+		}
+	})
+}
+
+// TestGetXpubTransactionDetails_Errors tests the failed and canceled requests
+func TestGetXpubTransactionDetails_Errors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("failed transaction", func(t *testing.T) {
+		xPub := "xpub-details-failed"
+		server, client := newFakeServer(t, noRetryOptions(), xpubHistory(xPub))
+		server.FailMatching(http.StatusServiceUnavailable, func(req bitindextest.Request) bool {
+			return req.Path == "tx/tx-spend"
+		})
+
+		if _, err := bitindex.GetXpubTransactionDetails(context.Background(), client, xPub, nil); !errors.Is(err, bitindex.ErrServerError) {
+			t.Fatalf("expected ErrServerError got: %v", err)
+		}
+	})
+
+	t.Run("failed chain info", func(t *testing.T) {
+		xPub := "xpub-details-chain"
+		server, client := newFakeServer(t, noRetryOptions(), xpubHistory(xPub))
+		server.FailMatching(http.StatusServiceUnavailable, func(req bitindextest.Request) bool {
+			return req.Path == "status"
+		})
+
+		if _, err := bitindex.GetXpubTransactionDetails(context.Background(), client, xPub, nil); !errors.Is(err, bitindex.ErrServerError) {
+			t.Fatalf("expected ErrServerError got: %v", err)
+		}
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		xPub := "xpub-details-canceled"
+		_, client := newFakeServer(t, nil, xpubHistory(xPub))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := bitindex.GetXpubTransactionDetails(ctx, client, xPub, nil); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled got: %v", err)
+		}
+	})
+}